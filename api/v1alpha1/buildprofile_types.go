@@ -0,0 +1,61 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BuildProfileSpec defines the defaults that are shared by all Repositories referencing the BuildProfile.
+type BuildProfileSpec struct {
+	// +optional
+	CloneUsingSsh *bool `json:"cloneUsingSsh,omitempty"`
+
+	// +optional
+	PipelineRunName string `json:"pipelineRunName,omitempty"`
+
+	// +kubebuilder:validation:Enum=Pipeline;Task;PipelineSpec
+	// +optional
+	BuildTarget BuildTarget `json:"buildTarget,omitempty"`
+
+	// +optional
+	PipelineResolver *PipelineResolver `json:"pipelineResolver,omitempty"`
+
+	// +optional
+	PipelineNames map[ArtifactType]string `json:"pipelineNames,omitempty"`
+
+	// +optional
+	VersionFilter *VersionFilterSpec `json:"versionFilter,omitempty"`
+
+	// +optional
+	Workspaces map[string]map[string]string `json:"workspaces,omitempty"`
+
+	// +optional
+	PipelineRunTemplate *PipelineRunTemplate `json:"pipelineRunTemplate,omitempty"`
+
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Params []Param `json:"params,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BuildProfile is the Schema for the buildprofiles API.
+type BuildProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec BuildProfileSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BuildProfileList contains a list of BuildProfile.
+type BuildProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BuildProfile `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BuildProfile{}, &BuildProfileList{})
+}
@@ -0,0 +1,71 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PipelineRunTemplate is merged into the PipelineRuns that are created for a Repository.
+type PipelineRunTemplate struct {
+	// Metadata is added to each PipelineRun.
+	// +optional
+	Metadata TemplateMetadata `json:"metadata,omitempty"`
+
+	// TaskRunTemplate configures the TaskRuns of all tasks of the pipeline.
+	// +optional
+	TaskRunTemplate *TaskRunTemplate `json:"taskRunTemplate,omitempty"`
+
+	// +optional
+	Timeouts *Timeouts `json:"timeouts,omitempty"`
+
+	// TaskRunSpecs override the TaskRun template for single tasks of the pipeline.
+	// +listType=map
+	// +listMapKey=pipelineTaskName
+	// +optional
+	TaskRunSpecs []TaskRunSpec `json:"taskRunSpecs,omitempty"`
+}
+
+type TaskRunTemplate struct {
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// +optional
+	PodTemplate *PodTemplate `json:"podTemplate,omitempty"`
+}
+
+type PodTemplate struct {
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// +optional
+	SecurityContext *corev1.PodSecurityContext `json:"securityContext,omitempty"`
+}
+
+type Timeouts struct {
+	// Pipeline is the timeout of the whole PipelineRun, including finally tasks.
+	// +optional
+	Pipeline *metav1.Duration `json:"pipeline,omitempty"`
+
+	// +optional
+	Tasks *metav1.Duration `json:"tasks,omitempty"`
+
+	// +optional
+	Finally *metav1.Duration `json:"finally,omitempty"`
+}
+
+type TaskRunSpec struct {
+	// PipelineTaskName is the name of the task within the pipeline.
+	PipelineTaskName string `json:"pipelineTaskName"`
+
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// +optional
+	PodTemplate *PodTemplate `json:"podTemplate,omitempty"`
+
+	// +optional
+	ComputeResources *corev1.ResourceRequirements `json:"computeResources,omitempty"`
+}
@@ -0,0 +1,4 @@
+package v1alpha1
+
+// Hub marks this type as a conversion hub.
+func (*Repository) Hub() {}
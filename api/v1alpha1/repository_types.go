@@ -18,22 +18,244 @@ func ArtifactTypes() []ArtifactType {
 	}
 }
 
+// Keys and values of the untyped workspace bindings of a Repository.
+const (
+	WorkspaceKeyType             = "type"
+	WorkspaceKeySecretName       = "secretName"
+	WorkspaceKeyConfigMapName    = "configMapName"
+	WorkspaceKeyClaimName        = "claimName"
+	WorkspaceKeyStorageClassName = "storageClassName"
+	WorkspaceKeySize             = "size"
+	WorkspaceKeyAccessModes      = "accessModes"
+	WorkspaceKeyItems            = "items"
+	WorkspaceKeySources          = "sources"
+	WorkspaceKeyDriver           = "driver"
+	WorkspaceKeyReadOnly         = "readOnly"
+	WorkspaceKeyVolumeAttributes = "volumeAttributes"
+
+	WorkspaceTypeSecret                = "secret"
+	WorkspaceTypeConfigMap             = "configMap"
+	WorkspaceTypeEmptyDir              = "emptyDir"
+	WorkspaceTypePersistentVolumeClaim = "persistentVolumeClaim"
+	WorkspaceTypeVolume                = "volume"
+	WorkspaceTypeProjected             = "projected"
+	WorkspaceTypeCSI                   = "csi"
+)
+
+type ProviderType string
+
+const (
+	ProviderGithub ProviderType = "github"
+	ProviderGitea  ProviderType = "gitea"
+)
+
 // RepositorySpec defines the desired state of Repository.
 type RepositorySpec struct {
-	Owner         string `json:"owner"`
-	Repository    string `json:"repo"`
-	CloneUsingSsh bool   `json:"cloneUsingSsh"`
+	Owner      string `json:"owner"`
+	Repository string `json:"repo"`
+
+	RepositoryConfig `json:",inline"`
+}
+
+// RepositoryConfig is the configuration of a Repository that does not depend on the watched repository. It is shared
+// with the templates of resources that generate Repositories.
+type RepositoryConfig struct {
+	// BuildProfileRef references a BuildProfile in the namespace of the Repository that provides defaults for the
+	// pipelines, pipeline resolver, workspaces, clone method, version filter and PipelineRun template. Fields set on
+	// the Repository take precedence.
+	// +optional
+	BuildProfileRef *LocalObjectReference `json:"buildProfileRef,omitempty"`
+
+	// +optional
+	CloneUsingSsh *bool `json:"cloneUsingSsh,omitempty"`
 
 	// +kubebuilder:default:=true
 	MemorizeReleases bool `json:"memorizeReleases"`
 
-	PipelineRunName string `json:"pipelineRunName"`
+	// +optional
+	PipelineRunName string `json:"pipelineRunName,omitempty"`
+
+	// BuildTarget selects whether the pipeline names refer to Pipelines, Tasks or ConfigMaps holding an inline
+	// pipelineSpec in the key "pipelineSpec". Defaults to Pipeline.
+	// +kubebuilder:validation:Enum=Pipeline;Task;PipelineSpec
+	// +optional
+	BuildTarget BuildTarget `json:"buildTarget,omitempty"`
+
+	// PipelineResolver resolves the pipelines using a Tekton remote resolver. The pipelines are not required to exist
+	// in the namespace of the Repository if set.
+	// +optional
+	PipelineResolver *PipelineResolver `json:"pipelineResolver,omitempty"`
+
+	// +optional
+	PipelineNames map[ArtifactType]string `json:"pipelineNames,omitempty"`
+
+	VersionFilter *VersionFilterSpec `json:"versionFilter,omitempty"`
+	OmitVersions  []string           `json:"omitVersions,omitempty"`
+
+	// +optional
+	Workspaces map[string]map[string]string `json:"workspaces,omitempty"`
+
+	// Provider selects the API that is queried for releases and artifacts. Defaults to GitHub.
+	// +optional
+	Provider *ProviderSpec `json:"provider,omitempty"`
+
+	// CredentialsSecretRef references a Secret in the namespace of the Repository that holds the token used to
+	// query the provider. The globally configured credentials are used if omitted.
+	// +optional
+	CredentialsSecretRef *SecretKeyReference `json:"credentialsSecretRef,omitempty"`
+
+	// Schedule configures how often the repository is polled. The globally configured defaults are used if omitted.
+	// +optional
+	Schedule *ScheduleSpec `json:"schedule,omitempty"`
+
+	// BuildWindow restricts the creation of PipelineRuns to the given windows, while missing artifacts are still
+	// detected around the clock. The globally configured build windows are used if omitted.
+	// +optional
+	BuildWindow *BuildWindowSpec `json:"buildWindow,omitempty"`
+
+	// PipelineRunTemplate is merged into the PipelineRuns that are created for the repository, e.g. to run them
+	// using a dedicated service account or on dedicated build nodes.
+	// +optional
+	PipelineRunTemplate *PipelineRunTemplate `json:"pipelineRunTemplate,omitempty"`
+
+	// Params are passed to the PipelineRuns in addition to the default params. Params with the name of a default
+	// param replace it.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Params []Param `json:"params,omitempty"`
+}
+
+type BuildWindowSpec struct {
+	// Windows PipelineRuns may be created in, e.g. "mon-fri 22:00-06:00" or "sat,sun 00:00-24:00". Windows whose end
+	// is before their start cross midnight.
+	// +kubebuilder:validation:MinItems=1
+	Windows []string `json:"windows"`
+
+	// TimeZone is the IANA time zone the windows are evaluated in. Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+type ScheduleSpec struct {
+	// Interval is the interval the repository is polled in, e.g. "5m" or "24h".
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// JitterPercent is the jitter in percent that is applied to the interval.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	JitterPercent *int `json:"jitterPercent,omitempty"`
+
+	// ActiveHours restricts polling to the given hours of the day.
+	// +optional
+	ActiveHours *ActiveHours `json:"activeHours,omitempty"`
+
+	// Cron is a cron expression in the standard five field format, e.g. "0 6 * * mon-fri". It takes precedence over
+	// the interval, jitter and active hours.
+	// +optional
+	Cron string `json:"cron,omitempty"`
+}
+
+// +kubebuilder:validation:XValidation:rule="self.from < self.to",message="from must be < to"
+type ActiveHours struct {
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=23
+	From int `json:"from"`
+
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=23
+	To int `json:"to"`
+}
+
+type LocalObjectReference struct {
+	Name string `json:"name"`
+}
+
+type SecretKeyReference struct {
+	Name string `json:"name"`
+
+	// +kubebuilder:default:=token
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// BuildTarget is the kind of the resources the pipeline names of a Repository refer to.
+type BuildTarget string
+
+const (
+	// BuildTargetPipeline creates PipelineRuns referencing the Pipeline.
+	BuildTargetPipeline BuildTarget = "Pipeline"
+	// BuildTargetTask creates TaskRuns referencing the Task.
+	BuildTargetTask BuildTarget = "Task"
+	// BuildTargetPipelineSpec creates PipelineRuns with the inline pipelineSpec stored in the key
+	// PipelineSpecConfigMapKey of the ConfigMap.
+	BuildTargetPipelineSpec BuildTarget = "PipelineSpec"
+
+	PipelineSpecConfigMapKey = "pipelineSpec"
+)
+
+// RunKind is the kind of a run that has been created for a release.
+type RunKind string
+
+const (
+	RunKindPipelineRun RunKind = "PipelineRun"
+	RunKindTaskRun     RunKind = "TaskRun"
+)
+
+// PipelineResolver references the Pipelines using a Tekton remote resolver instead of by name in the namespace of the
+// Repository.
+type PipelineResolver struct {
+	// +kubebuilder:validation:Enum=git;bundles;hub;cluster
+	Resolver string `json:"resolver"`
+
+	// Params are passed to the resolver. The pipeline name of the artifact type is passed as "pathInRepo" to the git
+	// resolver and as "name" to all other resolvers, unless set here. All resolvers but git also get the param "kind",
+	// which defaults to "task" for the build target Task and to "pipeline" otherwise.
+	// +optional
+	Params map[string]string `json:"params,omitempty"`
+}
+
+type ParamType string
+
+const (
+	ParamTypeString ParamType = "string"
+	ParamTypeArray  ParamType = "array"
+	ParamTypeObject ParamType = "object"
+)
 
-	PipelineNames map[ArtifactType]string `json:"pipelineNames"`
+// Param is passed to the PipelineRuns in addition to the default params. Its values are Go templates that are
+// rendered using the data of the release, e.g. "{{ .Version }}".
+type Param struct {
+	Name string `json:"name"`
 
-	VersionFilter *VersionFilterSpec           `json:"versionFilter,omitempty"`
-	OmitVersions  []string                     `json:"omitVersions,omitempty"`
-	Workspaces    map[string]map[string]string `json:"workspaces"`
+	// +kubebuilder:validation:Enum=string;array;object
+	// +kubebuilder:default:=string
+	// +optional
+	Type ParamType `json:"type,omitempty"`
+
+	// Value is the template of a string param.
+	// +optional
+	Value string `json:"value,omitempty"`
+
+	// Values are the templates of the items of an array param.
+	// +optional
+	Values []string `json:"values,omitempty"`
+
+	// Properties are the templates of the keys of an object param.
+	// +optional
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type ProviderSpec struct {
+	// +kubebuilder:validation:Enum=github;gitea
+	// +kubebuilder:default:=github
+	Type ProviderType `json:"type"`
+
+	// URL is the base URL of the instance, e.g. "https://codeberg.org". Required for Gitea/Forgejo.
+	// +optional
+	URL string `json:"url,omitempty"`
 }
 
 type VersionFilterSpec struct {
@@ -48,21 +270,46 @@ type RepositoryStatus struct {
 	Releases   map[string]*Release `json:"releases"`
 	Conditions []metav1.Condition  `json:"conditions,omitempty"`
 	LastCheck  *metav1.Time        `json:"lastCheck"`
+
+	// LastFullCheck is the time releases and artifacts have been fetched the last time.
+	// +optional
+	LastFullCheck *metav1.Time `json:"lastFullCheck,omitempty"`
+
+	// ObservedGeneration is the generation of the spec that has been used for the last full check.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// EffectiveSpec is the spec after merging the referenced BuildProfile.
+	// +optional
+	EffectiveSpec *RepositorySpec `json:"effectiveSpec,omitempty"`
+
+	// PollInterval is the interval learned from the release cadence of the repository.
+	// +optional
+	PollInterval *metav1.Duration `json:"pollInterval,omitempty"`
 }
 
 type Release struct {
 	MostRecentRuns   map[ArtifactType]*PipelineRun `json:"pipelineRuns,omitempty"`
 	MissingArtifacts map[ArtifactType]bool         `json:"missingArtifacts"`
+
+	// PublishedAt is the time the release has been published.
+	// +optional
+	PublishedAt *metav1.Time `json:"publishedAt,omitempty"`
 }
 
 type PipelineRun struct {
 	Name              string      `json:"name"`
 	CreationTimestamp metav1.Time `json:"timestamp,omitempty"`
 	RunsCreated       int         `json:"runsCreated"`
+
+	// Kind is the kind of the run. Defaults to PipelineRun.
+	// +optional
+	Kind RunKind `json:"kind,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="Owner",type=string,JSONPath=`.spec.owner`
 // +kubebuilder:printcolumn:name="Repo",type=string,JSONPath=`.spec.repo`
 // +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.status)].status`
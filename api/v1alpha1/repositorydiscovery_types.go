@@ -0,0 +1,96 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RepositoryDiscoverySpec defines the desired state of RepositoryDiscovery.
+type RepositoryDiscoverySpec struct {
+	// Owner is the GitHub organization or user whose repositories are discovered.
+	Owner string `json:"owner"`
+
+	// Filter restricts the discovered repositories. All repositories that are neither archived nor forks are
+	// discovered if omitted.
+	// +optional
+	Filter *DiscoveryFilter `json:"filter,omitempty"`
+
+	// Template is used to generate a Repository for each discovered repository.
+	Template RepositoryTemplate `json:"template"`
+
+	// Interval is the interval the repositories of the owner are listed in.
+	// +kubebuilder:default:="1h"
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// Prune deletes generated Repositories whose repository is not discovered anymore.
+	// +kubebuilder:default:=true
+	// +optional
+	Prune *bool `json:"prune,omitempty"`
+}
+
+type DiscoveryFilter struct {
+	// Topics selects repositories that have at least one of the given topics.
+	// +optional
+	Topics []string `json:"topics,omitempty"`
+
+	// NameRegex selects repositories whose name matches the regular expression.
+	// +optional
+	NameRegex string `json:"nameRegex,omitempty"`
+
+	// Languages selects repositories whose primary language is one of the given languages.
+	// +optional
+	Languages []string `json:"languages,omitempty"`
+
+	// IncludeArchived also selects archived repositories.
+	// +optional
+	IncludeArchived bool `json:"includeArchived,omitempty"`
+
+	// IncludeForks also selects forked repositories.
+	// +optional
+	IncludeForks bool `json:"includeForks,omitempty"`
+}
+
+// RepositoryDiscoveryStatus defines the observed state of RepositoryDiscovery.
+type RepositoryDiscoveryStatus struct {
+	// Repositories are the repositories that have been discovered, in the form "owner/repo".
+	// +optional
+	Repositories []string `json:"repositories,omitempty"`
+
+	// LastDiscovery is the time the repositories of the owner have been listed the last time.
+	// +optional
+	LastDiscovery *metav1.Time `json:"lastDiscovery,omitempty"`
+
+	// ObservedGeneration is the generation of the spec that has been used for the last discovery.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Owner",type=string,JSONPath=`.spec.owner`
+// +kubebuilder:printcolumn:name="Last Discovery",type=date,JSONPath=`.status.lastDiscovery`
+
+// RepositoryDiscovery is the Schema for the repositorydiscoveries API.
+type RepositoryDiscovery struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RepositoryDiscoverySpec   `json:"spec,omitempty"`
+	Status RepositoryDiscoveryStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RepositoryDiscoveryList contains a list of RepositoryDiscovery.
+type RepositoryDiscoveryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RepositoryDiscovery `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RepositoryDiscovery{}, &RepositoryDiscoveryList{})
+}
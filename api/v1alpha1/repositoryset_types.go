@@ -0,0 +1,83 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// RepositorySetSpec defines the desired state of RepositorySet.
+type RepositorySetSpec struct {
+	// Repositories is the list of repositories a Repository is generated for.
+	// +optional
+	Repositories []RepositorySetEntry `json:"repositories,omitempty"`
+
+	// ConfigMapRef references a ConfigMap in the namespace of the RepositorySet whose key holds additional entries
+	// as a YAML list, using the same format as repositories.
+	// +optional
+	ConfigMapRef *ConfigMapKeyReference `json:"configMapRef,omitempty"`
+
+	// Template is used to generate a Repository for each entry.
+	Template RepositoryTemplate `json:"template"`
+
+	// Prune deletes generated Repositories whose entry has been removed.
+	// +kubebuilder:default:=true
+	// +optional
+	Prune *bool `json:"prune,omitempty"`
+}
+
+type RepositorySetEntry struct {
+	Owner      string `json:"owner"`
+	Repository string `json:"repo"`
+
+	// Overrides is merged into the spec of the template, e.g. {"pipelineNames": {"container": "build-image"}}.
+	// Setting a field to null removes it.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +optional
+	Overrides *runtime.RawExtension `json:"overrides,omitempty"`
+}
+
+type ConfigMapKeyReference struct {
+	Name string `json:"name"`
+
+	// +kubebuilder:default:=repositories
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// RepositorySetStatus defines the observed state of RepositorySet.
+type RepositorySetStatus struct {
+	// Repositories are the repositories Repositories have been generated for, in the form "owner/repo".
+	// +optional
+	Repositories []string `json:"repositories,omitempty"`
+
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+
+// RepositorySet is the Schema for the repositorysets API.
+type RepositorySet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RepositorySetSpec   `json:"spec,omitempty"`
+	Status RepositorySetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RepositorySetList contains a list of RepositorySet.
+type RepositorySetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RepositorySet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RepositorySet{}, &RepositorySetList{})
+}
@@ -0,0 +1,18 @@
+package v1alpha1
+
+// RepositoryTemplate describes the Repositories that are generated by a RepositoryDiscovery or RepositorySet.
+type RepositoryTemplate struct {
+	// Metadata is added to each generated Repository.
+	// +optional
+	Metadata TemplateMetadata `json:"metadata,omitempty"`
+
+	Spec RepositoryConfig `json:"spec"`
+}
+
+type TemplateMetadata struct {
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
@@ -0,0 +1,594 @@
+package v1beta1
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/soerenschneider/gollum/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// UnconvertibleWorkspacesAnnotation holds the v1alpha1 workspaces that can not be represented in v1beta1, such as
+// workspaces of an unknown type or with unknown keys, so they are restored when converting back to v1alpha1.
+const UnconvertibleWorkspacesAnnotation = "gollum.soeren.cloud/unconvertible-workspaces"
+
+// ConvertTo converts this Repository (v1beta1) to the Hub version (v1alpha1).
+func (src *Repository) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.Repository)
+
+	dst.ObjectMeta = src.ObjectMeta
+	convertSpecTo(&src.Spec, &dst.Spec)
+	if err := restoreUnconvertibleWorkspaces(&dst.ObjectMeta, &dst.Spec); err != nil {
+		return err
+	}
+
+	dst.Status.Ready = src.Status.Ready
+	dst.Status.Conditions = src.Status.Conditions
+	dst.Status.LastCheck = src.Status.LastCheck
+	dst.Status.LastFullCheck = src.Status.LastFullCheck
+	dst.Status.ObservedGeneration = src.Status.ObservedGeneration
+	dst.Status.PollInterval = src.Status.PollInterval
+	dst.Status.Releases = convertReleasesTo(src.Status.Releases)
+	if src.Status.EffectiveSpec != nil {
+		dst.Status.EffectiveSpec = &v1alpha1.RepositorySpec{}
+		convertSpecTo(src.Status.EffectiveSpec, dst.Status.EffectiveSpec)
+	}
+
+	return nil
+}
+
+// ConvertFrom converts the Hub version (v1alpha1) to this version. Workspaces of an unknown type and unknown keys of
+// workspaces can not be represented, they are kept in the UnconvertibleWorkspacesAnnotation instead.
+func (dst *Repository) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.Repository)
+
+	dst.ObjectMeta = src.ObjectMeta
+	convertSpecFrom(&src.Spec, &dst.Spec)
+	if err := stashUnconvertibleWorkspaces(src.Spec.Workspaces, &dst.ObjectMeta); err != nil {
+		return err
+	}
+
+	dst.Status.Ready = src.Status.Ready
+	dst.Status.Conditions = src.Status.Conditions
+	dst.Status.LastCheck = src.Status.LastCheck
+	dst.Status.LastFullCheck = src.Status.LastFullCheck
+	dst.Status.ObservedGeneration = src.Status.ObservedGeneration
+	dst.Status.PollInterval = src.Status.PollInterval
+	dst.Status.Releases = convertReleasesFrom(src.Status.Releases)
+	if src.Status.EffectiveSpec != nil {
+		dst.Status.EffectiveSpec = &RepositorySpec{}
+		convertSpecFrom(src.Status.EffectiveSpec, dst.Status.EffectiveSpec)
+	}
+
+	return nil
+}
+
+func convertSpecTo(src *RepositorySpec, dst *v1alpha1.RepositorySpec) {
+	dst.Owner = src.Owner
+	dst.Repository = src.Repository
+	dst.CloneUsingSsh = src.CloneUsingSsh
+	dst.MemorizeReleases = src.MemorizeReleases
+	dst.PipelineRunName = src.PipelineRunName
+	dst.BuildTarget = v1alpha1.BuildTarget(src.BuildTarget)
+	dst.OmitVersions = src.OmitVersions
+
+	if src.BuildProfileRef != nil {
+		dst.BuildProfileRef = &v1alpha1.LocalObjectReference{Name: src.BuildProfileRef.Name}
+	}
+
+	if src.PipelineResolver != nil {
+		dst.PipelineResolver = &v1alpha1.PipelineResolver{Resolver: src.PipelineResolver.Resolver, Params: src.PipelineResolver.Params}
+	}
+
+	if len(src.Artifacts) > 0 {
+		dst.PipelineNames = make(map[v1alpha1.ArtifactType]string, len(src.Artifacts))
+		for _, artifact := range src.Artifacts {
+			dst.PipelineNames[v1alpha1.ArtifactType(artifact.Type)] = artifact.PipelineName
+		}
+	}
+
+	if src.VersionFilter != nil {
+		dst.VersionFilter = &v1alpha1.VersionFilterSpec{Impl: src.VersionFilter.Impl, Arg: src.VersionFilter.Arg}
+	}
+
+	if len(src.Workspaces) > 0 {
+		dst.Workspaces = make(map[string]map[string]string, len(src.Workspaces))
+		for _, workspace := range src.Workspaces {
+			dst.Workspaces[workspace.Name] = convertWorkspaceTo(workspace)
+		}
+	}
+
+	if src.Provider != nil {
+		dst.Provider = &v1alpha1.ProviderSpec{Type: v1alpha1.ProviderType(src.Provider.Type), URL: src.Provider.URL}
+	}
+
+	if src.CredentialsSecretRef != nil {
+		dst.CredentialsSecretRef = &v1alpha1.SecretKeyReference{Name: src.CredentialsSecretRef.Name, Key: src.CredentialsSecretRef.Key}
+	}
+
+	if src.Schedule != nil {
+		dst.Schedule = &v1alpha1.ScheduleSpec{
+			Interval:      src.Schedule.Interval,
+			JitterPercent: src.Schedule.JitterPercent,
+			Cron:          src.Schedule.Cron,
+		}
+		if src.Schedule.ActiveHours != nil {
+			dst.Schedule.ActiveHours = &v1alpha1.ActiveHours{From: src.Schedule.ActiveHours.From, To: src.Schedule.ActiveHours.To}
+		}
+	}
+
+	if src.BuildWindow != nil {
+		dst.BuildWindow = &v1alpha1.BuildWindowSpec{Windows: src.BuildWindow.Windows, TimeZone: src.BuildWindow.TimeZone}
+	}
+
+	dst.PipelineRunTemplate = convertPipelineRunTemplateTo(src.PipelineRunTemplate)
+
+	for _, param := range src.Params {
+		dst.Params = append(dst.Params, v1alpha1.Param{
+			Name:       param.Name,
+			Type:       v1alpha1.ParamType(param.Type),
+			Value:      param.Value,
+			Values:     param.Values,
+			Properties: param.Properties,
+		})
+	}
+}
+
+func convertSpecFrom(src *v1alpha1.RepositorySpec, dst *RepositorySpec) {
+	dst.Owner = src.Owner
+	dst.Repository = src.Repository
+	dst.CloneUsingSsh = src.CloneUsingSsh
+	dst.MemorizeReleases = src.MemorizeReleases
+	dst.PipelineRunName = src.PipelineRunName
+	dst.BuildTarget = BuildTarget(src.BuildTarget)
+	dst.OmitVersions = src.OmitVersions
+
+	if src.BuildProfileRef != nil {
+		dst.BuildProfileRef = &LocalObjectReference{Name: src.BuildProfileRef.Name}
+	}
+
+	if src.PipelineResolver != nil {
+		dst.PipelineResolver = &PipelineResolver{Resolver: src.PipelineResolver.Resolver, Params: src.PipelineResolver.Params}
+	}
+
+	for artifactType, pipelineName := range src.PipelineNames {
+		dst.Artifacts = append(dst.Artifacts, ArtifactSpec{Type: ArtifactType(artifactType), PipelineName: pipelineName})
+	}
+	slices.SortFunc(dst.Artifacts, func(a, b ArtifactSpec) int {
+		return cmp.Compare(a.Type, b.Type)
+	})
+
+	if src.VersionFilter != nil {
+		dst.VersionFilter = &VersionFilterSpec{Impl: src.VersionFilter.Impl, Arg: src.VersionFilter.Arg}
+	}
+
+	for name, values := range src.Workspaces {
+		if workspace, ok := convertWorkspaceFrom(name, values); ok {
+			dst.Workspaces = append(dst.Workspaces, workspace)
+		}
+	}
+	slices.SortFunc(dst.Workspaces, func(a, b WorkspaceBinding) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	if src.Provider != nil {
+		dst.Provider = &ProviderSpec{Type: ProviderType(src.Provider.Type), URL: src.Provider.URL}
+	}
+
+	if src.CredentialsSecretRef != nil {
+		dst.CredentialsSecretRef = &SecretKeyReference{Name: src.CredentialsSecretRef.Name, Key: src.CredentialsSecretRef.Key}
+	}
+
+	if src.Schedule != nil {
+		dst.Schedule = &ScheduleSpec{
+			Interval:      src.Schedule.Interval,
+			JitterPercent: src.Schedule.JitterPercent,
+			Cron:          src.Schedule.Cron,
+		}
+		if src.Schedule.ActiveHours != nil {
+			dst.Schedule.ActiveHours = &ActiveHours{From: src.Schedule.ActiveHours.From, To: src.Schedule.ActiveHours.To}
+		}
+	}
+
+	if src.BuildWindow != nil {
+		dst.BuildWindow = &BuildWindowSpec{Windows: src.BuildWindow.Windows, TimeZone: src.BuildWindow.TimeZone}
+	}
+
+	dst.PipelineRunTemplate = convertPipelineRunTemplateFrom(src.PipelineRunTemplate)
+
+	for _, param := range src.Params {
+		dst.Params = append(dst.Params, Param{
+			Name:       param.Name,
+			Type:       ParamType(param.Type),
+			Value:      param.Value,
+			Values:     param.Values,
+			Properties: param.Properties,
+		})
+	}
+}
+
+func convertPipelineRunTemplateTo(src *PipelineRunTemplate) *v1alpha1.PipelineRunTemplate {
+	if src == nil {
+		return nil
+	}
+
+	dst := &v1alpha1.PipelineRunTemplate{
+		Metadata: v1alpha1.TemplateMetadata{Labels: src.Metadata.Labels, Annotations: src.Metadata.Annotations},
+	}
+	if src.TaskRunTemplate != nil {
+		dst.TaskRunTemplate = &v1alpha1.TaskRunTemplate{
+			ServiceAccountName: src.TaskRunTemplate.ServiceAccountName,
+			PodTemplate:        convertPodTemplateTo(src.TaskRunTemplate.PodTemplate),
+		}
+	}
+	if src.Timeouts != nil {
+		dst.Timeouts = &v1alpha1.Timeouts{Pipeline: src.Timeouts.Pipeline, Tasks: src.Timeouts.Tasks, Finally: src.Timeouts.Finally}
+	}
+	for _, taskRunSpec := range src.TaskRunSpecs {
+		dst.TaskRunSpecs = append(dst.TaskRunSpecs, v1alpha1.TaskRunSpec{
+			PipelineTaskName:   taskRunSpec.PipelineTaskName,
+			ServiceAccountName: taskRunSpec.ServiceAccountName,
+			PodTemplate:        convertPodTemplateTo(taskRunSpec.PodTemplate),
+			ComputeResources:   taskRunSpec.ComputeResources,
+		})
+	}
+	return dst
+}
+
+func convertPodTemplateTo(src *PodTemplate) *v1alpha1.PodTemplate {
+	if src == nil {
+		return nil
+	}
+	return &v1alpha1.PodTemplate{NodeSelector: src.NodeSelector, Tolerations: src.Tolerations, SecurityContext: src.SecurityContext}
+}
+
+func convertPipelineRunTemplateFrom(src *v1alpha1.PipelineRunTemplate) *PipelineRunTemplate {
+	if src == nil {
+		return nil
+	}
+
+	dst := &PipelineRunTemplate{
+		Metadata: TemplateMetadata{Labels: src.Metadata.Labels, Annotations: src.Metadata.Annotations},
+	}
+	if src.TaskRunTemplate != nil {
+		dst.TaskRunTemplate = &TaskRunTemplate{
+			ServiceAccountName: src.TaskRunTemplate.ServiceAccountName,
+			PodTemplate:        convertPodTemplateFrom(src.TaskRunTemplate.PodTemplate),
+		}
+	}
+	if src.Timeouts != nil {
+		dst.Timeouts = &Timeouts{Pipeline: src.Timeouts.Pipeline, Tasks: src.Timeouts.Tasks, Finally: src.Timeouts.Finally}
+	}
+	for _, taskRunSpec := range src.TaskRunSpecs {
+		dst.TaskRunSpecs = append(dst.TaskRunSpecs, TaskRunSpec{
+			PipelineTaskName:   taskRunSpec.PipelineTaskName,
+			ServiceAccountName: taskRunSpec.ServiceAccountName,
+			PodTemplate:        convertPodTemplateFrom(taskRunSpec.PodTemplate),
+			ComputeResources:   taskRunSpec.ComputeResources,
+		})
+	}
+	return dst
+}
+
+func convertPodTemplateFrom(src *v1alpha1.PodTemplate) *PodTemplate {
+	if src == nil {
+		return nil
+	}
+	return &PodTemplate{NodeSelector: src.NodeSelector, Tolerations: src.Tolerations, SecurityContext: src.SecurityContext}
+}
+
+func convertWorkspaceTo(src WorkspaceBinding) map[string]string {
+	var ret map[string]string
+	switch {
+	case src.Secret != nil:
+		ret = map[string]string{
+			v1alpha1.WorkspaceKeyType:       v1alpha1.WorkspaceTypeSecret,
+			v1alpha1.WorkspaceKeySecretName: src.Secret.SecretName,
+		}
+		setIfNotEmpty(ret, v1alpha1.WorkspaceKeyItems, joinItems(src.Secret.Items))
+	case src.ConfigMap != nil:
+		ret = map[string]string{
+			v1alpha1.WorkspaceKeyType:          v1alpha1.WorkspaceTypeConfigMap,
+			v1alpha1.WorkspaceKeyConfigMapName: src.ConfigMap.Name,
+		}
+		setIfNotEmpty(ret, v1alpha1.WorkspaceKeyItems, joinItems(src.ConfigMap.Items))
+	case src.EmptyDir != nil:
+		ret = map[string]string{
+			v1alpha1.WorkspaceKeyType: v1alpha1.WorkspaceTypeEmptyDir,
+		}
+	case src.PersistentVolumeClaim != nil:
+		ret = map[string]string{
+			v1alpha1.WorkspaceKeyType:      v1alpha1.WorkspaceTypePersistentVolumeClaim,
+			v1alpha1.WorkspaceKeyClaimName: src.PersistentVolumeClaim.ClaimName,
+		}
+	case src.VolumeClaimTemplate != nil:
+		ret = map[string]string{
+			v1alpha1.WorkspaceKeyType: v1alpha1.WorkspaceTypeVolume,
+		}
+		setIfNotEmpty(ret, v1alpha1.WorkspaceKeyStorageClassName, src.VolumeClaimTemplate.StorageClassName)
+		if src.VolumeClaimTemplate.Size != nil {
+			ret[v1alpha1.WorkspaceKeySize] = src.VolumeClaimTemplate.Size.String()
+		}
+		accessModes := make([]string, 0, len(src.VolumeClaimTemplate.AccessModes))
+		for _, mode := range src.VolumeClaimTemplate.AccessModes {
+			accessModes = append(accessModes, string(mode))
+		}
+		setIfNotEmpty(ret, v1alpha1.WorkspaceKeyAccessModes, strings.Join(accessModes, ","))
+	case src.Projected != nil:
+		sources := make([]string, 0, len(src.Projected.Sources))
+		for _, source := range src.Projected.Sources {
+			if source.Secret != nil {
+				sources = append(sources, v1alpha1.WorkspaceTypeSecret+"/"+source.Secret.Name)
+			} else if source.ConfigMap != nil {
+				sources = append(sources, v1alpha1.WorkspaceTypeConfigMap+"/"+source.ConfigMap.Name)
+			}
+		}
+		ret = map[string]string{
+			v1alpha1.WorkspaceKeyType:    v1alpha1.WorkspaceTypeProjected,
+			v1alpha1.WorkspaceKeySources: strings.Join(sources, ","),
+		}
+	case src.CSI != nil:
+		ret = map[string]string{
+			v1alpha1.WorkspaceKeyType:   v1alpha1.WorkspaceTypeCSI,
+			v1alpha1.WorkspaceKeyDriver: src.CSI.Driver,
+		}
+		if src.CSI.ReadOnly != nil {
+			ret[v1alpha1.WorkspaceKeyReadOnly] = strconv.FormatBool(*src.CSI.ReadOnly)
+		}
+		attributes := make([]string, 0, len(src.CSI.VolumeAttributes))
+		for key, value := range src.CSI.VolumeAttributes {
+			attributes = append(attributes, key+"="+value)
+		}
+		slices.Sort(attributes)
+		setIfNotEmpty(ret, v1alpha1.WorkspaceKeyVolumeAttributes, strings.Join(attributes, ","))
+	default:
+		ret = map[string]string{}
+	}
+	return ret
+}
+
+func convertWorkspaceFrom(name string, src map[string]string) (WorkspaceBinding, bool) {
+	ret := WorkspaceBinding{Name: name}
+
+	switch src[v1alpha1.WorkspaceKeyType] {
+	case v1alpha1.WorkspaceTypeSecret:
+		ret.Secret = &SecretWorkspace{
+			SecretName: src[v1alpha1.WorkspaceKeySecretName],
+			Items:      splitItems(src[v1alpha1.WorkspaceKeyItems]),
+		}
+	case v1alpha1.WorkspaceTypeConfigMap:
+		ret.ConfigMap = &ConfigMapWorkspace{
+			Name:  src[v1alpha1.WorkspaceKeyConfigMapName],
+			Items: splitItems(src[v1alpha1.WorkspaceKeyItems]),
+		}
+	case v1alpha1.WorkspaceTypeEmptyDir:
+		ret.EmptyDir = &EmptyDirWorkspace{}
+	case v1alpha1.WorkspaceTypePersistentVolumeClaim:
+		ret.PersistentVolumeClaim = &PersistentVolumeClaimWorkspace{ClaimName: src[v1alpha1.WorkspaceKeyClaimName]}
+	case v1alpha1.WorkspaceTypeVolume:
+		ret.VolumeClaimTemplate = &VolumeClaimTemplateWorkspace{StorageClassName: src[v1alpha1.WorkspaceKeyStorageClassName]}
+		if size, err := resource.ParseQuantity(src[v1alpha1.WorkspaceKeySize]); err == nil {
+			ret.VolumeClaimTemplate.Size = &size
+		}
+		for _, mode := range splitList(src[v1alpha1.WorkspaceKeyAccessModes]) {
+			ret.VolumeClaimTemplate.AccessModes = append(ret.VolumeClaimTemplate.AccessModes, corev1.PersistentVolumeAccessMode(mode))
+		}
+	case v1alpha1.WorkspaceTypeProjected:
+		ret.Projected = &ProjectedWorkspace{}
+		for _, source := range splitList(src[v1alpha1.WorkspaceKeySources]) {
+			kind, sourceName, _ := strings.Cut(source, "/")
+			switch kind {
+			case v1alpha1.WorkspaceTypeSecret:
+				ret.Projected.Sources = append(ret.Projected.Sources, ProjectedSource{Secret: &LocalObjectReference{Name: sourceName}})
+			case v1alpha1.WorkspaceTypeConfigMap:
+				ret.Projected.Sources = append(ret.Projected.Sources, ProjectedSource{ConfigMap: &LocalObjectReference{Name: sourceName}})
+			}
+		}
+	case v1alpha1.WorkspaceTypeCSI:
+		ret.CSI = &CSIWorkspace{Driver: src[v1alpha1.WorkspaceKeyDriver]}
+		if readOnly, err := strconv.ParseBool(src[v1alpha1.WorkspaceKeyReadOnly]); err == nil {
+			ret.CSI.ReadOnly = &readOnly
+		}
+		for _, attribute := range splitList(src[v1alpha1.WorkspaceKeyVolumeAttributes]) {
+			if key, value, found := strings.Cut(attribute, "="); found {
+				if ret.CSI.VolumeAttributes == nil {
+					ret.CSI.VolumeAttributes = map[string]string{}
+				}
+				ret.CSI.VolumeAttributes[key] = value
+			}
+		}
+	default:
+		return ret, false
+	}
+
+	return ret, true
+}
+
+// stashUnconvertibleWorkspaces stores the workspaces that do not survive the conversion to v1beta1 unchanged in an
+// annotation.
+func stashUnconvertibleWorkspaces(workspaces map[string]map[string]string, meta *metav1.ObjectMeta) error {
+	meta.Annotations = maps.Clone(meta.Annotations)
+	delete(meta.Annotations, UnconvertibleWorkspacesAnnotation)
+
+	unconvertible := map[string]map[string]string{}
+	for name, values := range workspaces {
+		workspace, ok := convertWorkspaceFrom(name, values)
+		if !ok || !maps.Equal(convertWorkspaceTo(workspace), values) {
+			unconvertible[name] = values
+		}
+	}
+
+	if len(unconvertible) > 0 {
+		data, err := json.Marshal(unconvertible)
+		if err != nil {
+			return fmt.Errorf("could not marshal unconvertible workspaces: %w", err)
+		}
+		if meta.Annotations == nil {
+			meta.Annotations = map[string]string{}
+		}
+		meta.Annotations[UnconvertibleWorkspacesAnnotation] = string(data)
+	}
+
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+	return nil
+}
+
+// restoreUnconvertibleWorkspaces restores the workspaces stored by stashUnconvertibleWorkspaces. Workspaces that have
+// been added, changed or removed in v1beta1 take precedence over the stored ones.
+func restoreUnconvertibleWorkspaces(meta *metav1.ObjectMeta, spec *v1alpha1.RepositorySpec) error {
+	data, found := meta.Annotations[UnconvertibleWorkspacesAnnotation]
+	if !found {
+		return nil
+	}
+
+	meta.Annotations = maps.Clone(meta.Annotations)
+	delete(meta.Annotations, UnconvertibleWorkspacesAnnotation)
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+
+	var unconvertible map[string]map[string]string
+	if err := json.Unmarshal([]byte(data), &unconvertible); err != nil {
+		return fmt.Errorf("could not parse annotation %s: %w", UnconvertibleWorkspacesAnnotation, err)
+	}
+
+	for name, values := range unconvertible {
+		workspace, ok := convertWorkspaceFrom(name, values)
+		current, exists := spec.Workspaces[name]
+
+		// workspaces of an unknown type are absent in v1beta1, others are only restored if they have not been changed
+		if (!ok && !exists) || (ok && exists && maps.Equal(current, convertWorkspaceTo(workspace))) {
+			if spec.Workspaces == nil {
+				spec.Workspaces = map[string]map[string]string{}
+			}
+			spec.Workspaces[name] = values
+		}
+	}
+	return nil
+}
+
+func joinItems(items []KeyToPath) string {
+	ret := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Path == "" {
+			ret = append(ret, item.Key)
+		} else {
+			ret = append(ret, item.Key+"="+item.Path)
+		}
+	}
+	return strings.Join(ret, ",")
+}
+
+func splitItems(value string) []KeyToPath {
+	var ret []KeyToPath
+	for _, item := range splitList(value) {
+		key, path, _ := strings.Cut(item, "=")
+		ret = append(ret, KeyToPath{Key: key, Path: path})
+	}
+	return ret
+}
+
+func splitList(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+
+	var ret []string
+	for _, item := range strings.Split(value, ",") {
+		ret = append(ret, strings.TrimSpace(item))
+	}
+	return ret
+}
+
+func setIfNotEmpty(values map[string]string, key, value string) {
+	if value != "" {
+		values[key] = value
+	}
+}
+
+func convertReleasesTo(src []ReleaseStatus) map[string]*v1alpha1.Release {
+	if src == nil {
+		return nil
+	}
+
+	ret := make(map[string]*v1alpha1.Release, len(src))
+	for _, release := range src {
+		converted := &v1alpha1.Release{
+			PublishedAt:      release.PublishedAt,
+			MissingArtifacts: make(map[v1alpha1.ArtifactType]bool, len(release.Artifacts)),
+		}
+		for _, artifact := range release.Artifacts {
+			artifactType := v1alpha1.ArtifactType(artifact.Type)
+			converted.MissingArtifacts[artifactType] = artifact.Missing
+			if artifact.LastPipelineRun != nil {
+				if converted.MostRecentRuns == nil {
+					converted.MostRecentRuns = map[v1alpha1.ArtifactType]*v1alpha1.PipelineRun{}
+				}
+				converted.MostRecentRuns[artifactType] = &v1alpha1.PipelineRun{
+					Name:              artifact.LastPipelineRun.Name,
+					CreationTimestamp: artifact.LastPipelineRun.CreationTimestamp,
+					RunsCreated:       artifact.LastPipelineRun.RunsCreated,
+					Kind:              v1alpha1.RunKind(artifact.LastPipelineRun.Kind),
+				}
+			}
+		}
+		ret[release.Tag] = converted
+	}
+	return ret
+}
+
+func convertReleasesFrom(src map[string]*v1alpha1.Release) []ReleaseStatus {
+	if src == nil {
+		return nil
+	}
+
+	ret := make([]ReleaseStatus, 0, len(src))
+	for tag, release := range src {
+		converted := ReleaseStatus{Tag: tag}
+		if release == nil {
+			ret = append(ret, converted)
+			continue
+		}
+		converted.PublishedAt = release.PublishedAt
+
+		artifactTypes := make([]v1alpha1.ArtifactType, 0, len(release.MissingArtifacts))
+		for artifactType := range release.MissingArtifacts {
+			artifactTypes = append(artifactTypes, artifactType)
+		}
+		for artifactType, run := range release.MostRecentRuns {
+			if _, found := release.MissingArtifacts[artifactType]; !found && run != nil {
+				artifactTypes = append(artifactTypes, artifactType)
+			}
+		}
+		slices.Sort(artifactTypes)
+
+		for _, artifactType := range artifactTypes {
+			artifact := ArtifactStatus{Type: ArtifactType(artifactType), Missing: release.MissingArtifacts[artifactType]}
+			if run := release.MostRecentRuns[artifactType]; run != nil {
+				artifact.LastPipelineRun = &PipelineRunStatus{
+					Name:              run.Name,
+					CreationTimestamp: run.CreationTimestamp,
+					RunsCreated:       run.RunsCreated,
+					Kind:              RunKind(run.Kind),
+				}
+			}
+			converted.Artifacts = append(converted.Artifacts, artifact)
+		}
+		ret = append(ret, converted)
+	}
+
+	slices.SortFunc(ret, func(a, b ReleaseStatus) int {
+		return cmp.Compare(a.Tag, b.Tag)
+	})
+	return ret
+}
@@ -0,0 +1,256 @@
+package v1beta1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/soerenschneider/gollum/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRepository_ConvertRoundTrip(t *testing.T) {
+	size := resource.MustParse("2Gi")
+	now := metav1.Now()
+	readOnly := true
+
+	tests := []struct {
+		name string
+		repo *Repository
+	}{
+		{
+			name: "minimal",
+			repo: &Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "tunnelguard", Namespace: "default"},
+				Spec: RepositorySpec{
+					Owner:      "soerenschneider",
+					Repository: "tunnelguard",
+				},
+			},
+		},
+		{
+			name: "all workspace types",
+			repo: &Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "tunnelguard", Namespace: "default"},
+				Spec: RepositorySpec{
+					Owner:            "soerenschneider",
+					Repository:       "tunnelguard",
+					MemorizeReleases: true,
+					Artifacts: []ArtifactSpec{
+						{Type: ArtifactTypeReleaseAssets, PipelineName: "build-gh-release"},
+						{Type: ArtifactTypeContainer, PipelineName: "build-image"},
+					},
+					VersionFilter: &VersionFilterSpec{Impl: "semver", Arg: ">= v1.0.0"},
+					Workspaces: []WorkspaceBinding{
+						{Name: "cache", EmptyDir: &EmptyDirWorkspace{}},
+						{Name: "config", ConfigMap: &ConfigMapWorkspace{Name: "build-config", Items: []KeyToPath{{Key: "goreleaser.yaml"}}}},
+						{Name: "credentials", Projected: &ProjectedWorkspace{Sources: []ProjectedSource{
+							{Secret: &LocalObjectReference{Name: "github"}},
+							{ConfigMap: &LocalObjectReference{Name: "ca-bundle"}},
+						}}},
+						{Name: "output", PersistentVolumeClaim: &PersistentVolumeClaimWorkspace{ClaimName: "output"}},
+						{Name: "secrets-store", CSI: &CSIWorkspace{Driver: "secrets-store.csi.k8s.io", ReadOnly: &readOnly, VolumeAttributes: map[string]string{"secretProviderClass": "vault"}}},
+						{Name: "shared-data", VolumeClaimTemplate: &VolumeClaimTemplateWorkspace{
+							StorageClassName: "openebs-hostpath",
+							Size:             &size,
+							AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce, corev1.ReadWriteMany},
+						}},
+						{Name: "signify", Secret: &SecretWorkspace{SecretName: "signify", Items: []KeyToPath{{Key: "key", Path: "signify.sec"}}}},
+					},
+					Schedule: &ScheduleSpec{Cron: "0 6 * * mon-fri", ActiveHours: &ActiveHours{From: 6, To: 22}},
+					PipelineRunTemplate: &PipelineRunTemplate{
+						Metadata: TemplateMetadata{Labels: map[string]string{"team": "platform"}},
+						TaskRunTemplate: &TaskRunTemplate{
+							ServiceAccountName: "builder",
+							PodTemplate: &PodTemplate{
+								NodeSelector: map[string]string{"node-role": "build"},
+								Tolerations:  []corev1.Toleration{{Key: "build", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
+							},
+						},
+						Timeouts:     &Timeouts{Pipeline: &metav1.Duration{Duration: time.Hour}},
+						TaskRunSpecs: []TaskRunSpec{{PipelineTaskName: "build", ServiceAccountName: "image-builder"}},
+					},
+					BuildTarget: BuildTargetTask,
+					PipelineResolver: &PipelineResolver{
+						Resolver: "bundles",
+						Params:   map[string]string{"bundle": "registry.example.com/pipelines:v1"},
+					},
+					Params: []Param{
+						{Name: "version", Type: ParamTypeString, Value: "{{ .Version }}"},
+						{Name: "platforms", Type: ParamTypeArray, Values: []string{"linux/amd64", "linux/arm64"}},
+					},
+				},
+			},
+		},
+		{
+			name: "status",
+			repo: &Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "tunnelguard", Namespace: "default"},
+				Spec: RepositorySpec{
+					Owner:      "soerenschneider",
+					Repository: "tunnelguard",
+				},
+				Status: RepositoryStatus{
+					Ready:     true,
+					LastCheck: &now,
+					Releases: []ReleaseStatus{
+						{
+							Tag: "v1.0.0",
+							Artifacts: []ArtifactStatus{
+								{Type: ArtifactTypeReleaseAssets, Missing: false},
+								{Type: ArtifactTypeContainer, Missing: true, LastPipelineRun: &PipelineRunStatus{Name: "gollum-v1.0.0", RunsCreated: 2, Kind: RunKindTaskRun}},
+							},
+						},
+						{
+							Tag:         "v1.1.0",
+							PublishedAt: &now,
+							Artifacts:   []ArtifactStatus{{Type: ArtifactTypeReleaseAssets, Missing: true}},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := &v1alpha1.Repository{}
+			if err := tt.repo.ConvertTo(hub); err != nil {
+				t.Fatalf("ConvertTo() error = %v", err)
+			}
+
+			got := &Repository{}
+			if err := got.ConvertFrom(hub); err != nil {
+				t.Fatalf("ConvertFrom() error = %v", err)
+			}
+
+			// lists of the test cases are sorted like the converted ones
+			want := tt.repo
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestRepository_ConvertFrom(t *testing.T) {
+	hub := &v1alpha1.Repository{
+		Spec: v1alpha1.RepositorySpec{
+			Owner:      "soerenschneider",
+			Repository: "tunnelguard",
+			RepositoryConfig: v1alpha1.RepositoryConfig{
+				Workspaces: map[string]map[string]string{
+					"shared-data": {"type": "volume", "storageClassname": "typo"},
+					"unknown":     {"type": "nfs"},
+				},
+			},
+		},
+	}
+
+	got := &Repository{}
+	if err := got.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+
+	want := []WorkspaceBinding{{Name: "shared-data", VolumeClaimTemplate: &VolumeClaimTemplateWorkspace{}}}
+	if !reflect.DeepEqual(got.Spec.Workspaces, want) {
+		t.Errorf("ConvertFrom() workspaces = %+v, want %+v", got.Spec.Workspaces, want)
+	}
+
+	wantAnnotation := `{"shared-data":{"storageClassname":"typo","type":"volume"},"unknown":{"type":"nfs"}}`
+	if got := got.Annotations[UnconvertibleWorkspacesAnnotation]; got != wantAnnotation {
+		t.Errorf("ConvertFrom() annotation = %s, want %s", got, wantAnnotation)
+	}
+}
+
+func TestRepository_ConvertHubRoundTrip(t *testing.T) {
+	newHub := func() *v1alpha1.Repository {
+		return &v1alpha1.Repository{
+			ObjectMeta: metav1.ObjectMeta{Name: "tunnelguard", Namespace: "default", Annotations: map[string]string{"note": "keep"}},
+			Spec: v1alpha1.RepositorySpec{
+				Owner:      "soerenschneider",
+				Repository: "tunnelguard",
+				RepositoryConfig: v1alpha1.RepositoryConfig{
+					Workspaces: map[string]map[string]string{
+						"cache":       {"type": "emptyDir"},
+						"shared-data": {"type": "volume", "storageClassname": "typo"},
+						"unknown":     {"type": "nfs", "server": "nas.local"},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(spoke *Repository)
+		want   map[string]map[string]string
+	}{
+		{
+			name: "unchanged",
+			want: newHub().Spec.Workspaces,
+		},
+		{
+			name: "workspace with unknown keys changed",
+			modify: func(spoke *Repository) {
+				spoke.Spec.Workspaces[1].VolumeClaimTemplate.StorageClassName = "openebs-hostpath"
+			},
+			want: map[string]map[string]string{
+				"cache":       {"type": "emptyDir"},
+				"shared-data": {"type": "volume", "storageClassName": "openebs-hostpath"},
+				"unknown":     {"type": "nfs", "server": "nas.local"},
+			},
+		},
+		{
+			name: "workspace with unknown keys removed",
+			modify: func(spoke *Repository) {
+				spoke.Spec.Workspaces = spoke.Spec.Workspaces[:1]
+			},
+			want: map[string]map[string]string{
+				"cache":   {"type": "emptyDir"},
+				"unknown": {"type": "nfs", "server": "nas.local"},
+			},
+		},
+		{
+			name: "workspace of unknown type replaced",
+			modify: func(spoke *Repository) {
+				spoke.Spec.Workspaces = append(spoke.Spec.Workspaces, WorkspaceBinding{Name: "unknown", EmptyDir: &EmptyDirWorkspace{}})
+			},
+			want: map[string]map[string]string{
+				"cache":       {"type": "emptyDir"},
+				"shared-data": {"type": "volume", "storageClassname": "typo"},
+				"unknown":     {"type": "emptyDir"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := newHub()
+
+			spoke := &Repository{}
+			if err := spoke.ConvertFrom(hub); err != nil {
+				t.Fatalf("ConvertFrom() error = %v", err)
+			}
+			if _, found := hub.Annotations[UnconvertibleWorkspacesAnnotation]; found {
+				t.Fatal("expected annotations of the hub to be unchanged")
+			}
+			if tt.modify != nil {
+				tt.modify(spoke)
+			}
+
+			got := &v1alpha1.Repository{}
+			if err := spoke.ConvertTo(got); err != nil {
+				t.Fatalf("ConvertTo() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got.Spec.Workspaces, tt.want) {
+				t.Errorf("round trip workspaces = %v, want %v", got.Spec.Workspaces, tt.want)
+			}
+			if !reflect.DeepEqual(got.Annotations, map[string]string{"note": "keep"}) {
+				t.Errorf("round trip annotations = %v, want only the original annotations", got.Annotations)
+			}
+		})
+	}
+}
@@ -0,0 +1,459 @@
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type ArtifactType string
+
+const (
+	ArtifactTypeReleaseAssets ArtifactType = "assets"
+	ArtifactTypeContainer     ArtifactType = "container"
+)
+
+type ProviderType string
+
+const (
+	ProviderGithub ProviderType = "github"
+	ProviderGitea  ProviderType = "gitea"
+)
+
+// RepositorySpec defines the desired state of Repository.
+type RepositorySpec struct {
+	// +kubebuilder:validation:MinLength=1
+	Owner string `json:"owner"`
+
+	// +kubebuilder:validation:MinLength=1
+	Repository string `json:"repo"`
+
+	// BuildProfileRef references a BuildProfile in the namespace of the Repository that provides defaults for the
+	// pipelines, pipeline resolver, workspaces, clone method, version filter and PipelineRun template. Fields set on
+	// the Repository take precedence.
+	// +optional
+	BuildProfileRef *LocalObjectReference `json:"buildProfileRef,omitempty"`
+
+	// +optional
+	CloneUsingSsh *bool `json:"cloneUsingSsh,omitempty"`
+
+	// +kubebuilder:default:=true
+	MemorizeReleases bool `json:"memorizeReleases"`
+
+	// +optional
+	PipelineRunName string `json:"pipelineRunName,omitempty"`
+
+	// BuildTarget selects whether the pipeline names refer to Pipelines, Tasks or ConfigMaps holding an inline
+	// pipelineSpec in the key "pipelineSpec". Defaults to Pipeline.
+	// +kubebuilder:validation:Enum=Pipeline;Task;PipelineSpec
+	// +optional
+	BuildTarget BuildTarget `json:"buildTarget,omitempty"`
+
+	// PipelineResolver resolves the pipelines using a Tekton remote resolver. The pipelines are not required to exist
+	// in the namespace of the Repository if set.
+	// +optional
+	PipelineResolver *PipelineResolver `json:"pipelineResolver,omitempty"`
+
+	// Artifacts lists the artifacts that are expected for each release and the pipelines that build them.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Artifacts []ArtifactSpec `json:"artifacts,omitempty"`
+
+	// +optional
+	VersionFilter *VersionFilterSpec `json:"versionFilter,omitempty"`
+
+	// +optional
+	OmitVersions []string `json:"omitVersions,omitempty"`
+
+	// Workspaces binds the workspaces of the pipelines to volumes.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Workspaces []WorkspaceBinding `json:"workspaces,omitempty"`
+
+	// Provider selects the API that is queried for releases and artifacts. Defaults to GitHub.
+	// +optional
+	Provider *ProviderSpec `json:"provider,omitempty"`
+
+	// CredentialsSecretRef references a Secret in the namespace of the Repository that holds the token used to
+	// query the provider. The globally configured credentials are used if omitted.
+	// +optional
+	CredentialsSecretRef *SecretKeyReference `json:"credentialsSecretRef,omitempty"`
+
+	// Schedule configures how often the repository is polled. The globally configured defaults are used if omitted.
+	// +optional
+	Schedule *ScheduleSpec `json:"schedule,omitempty"`
+
+	// BuildWindow restricts the creation of PipelineRuns to the given windows, while missing artifacts are still
+	// detected around the clock. The globally configured build windows are used if omitted.
+	// +optional
+	BuildWindow *BuildWindowSpec `json:"buildWindow,omitempty"`
+
+	// PipelineRunTemplate is merged into the PipelineRuns that are created for the repository, e.g. to run them
+	// using a dedicated service account or on dedicated build nodes.
+	// +optional
+	PipelineRunTemplate *PipelineRunTemplate `json:"pipelineRunTemplate,omitempty"`
+
+	// Params are passed to the PipelineRuns in addition to the default params. Params with the name of a default
+	// param replace it.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Params []Param `json:"params,omitempty"`
+}
+
+type ArtifactSpec struct {
+	// +kubebuilder:validation:Enum=assets;container
+	Type ArtifactType `json:"type"`
+
+	// PipelineName is the name of the Tekton Pipeline that builds the artifact.
+	// +kubebuilder:validation:MinLength=1
+	PipelineName string `json:"pipelineName"`
+}
+
+// WorkspaceBinding binds a workspace of the pipelines to a volume. Exactly one volume source must be set.
+// +kubebuilder:validation:XValidation:rule="[has(self.secret), has(self.configMap), has(self.emptyDir), has(self.persistentVolumeClaim), has(self.volumeClaimTemplate), has(self.projected), has(self.csi)].filter(x, x).size() == 1",message="exactly one volume source must be set"
+type WorkspaceBinding struct {
+	// Name is the name of the workspace as declared by the pipelines.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// +optional
+	Secret *SecretWorkspace `json:"secret,omitempty"`
+
+	// +optional
+	ConfigMap *ConfigMapWorkspace `json:"configMap,omitempty"`
+
+	// +optional
+	EmptyDir *EmptyDirWorkspace `json:"emptyDir,omitempty"`
+
+	// PersistentVolumeClaim binds an existing PersistentVolumeClaim.
+	// +optional
+	PersistentVolumeClaim *PersistentVolumeClaimWorkspace `json:"persistentVolumeClaim,omitempty"`
+
+	// VolumeClaimTemplate creates a PersistentVolumeClaim for each PipelineRun.
+	// +optional
+	VolumeClaimTemplate *VolumeClaimTemplateWorkspace `json:"volumeClaimTemplate,omitempty"`
+
+	// Projected combines Secrets and ConfigMaps in a single volume.
+	// +optional
+	Projected *ProjectedWorkspace `json:"projected,omitempty"`
+
+	// +optional
+	CSI *CSIWorkspace `json:"csi,omitempty"`
+}
+
+type SecretWorkspace struct {
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
+
+	// Items selects the keys of the Secret that are mounted. All keys are mounted if omitted.
+	// +optional
+	Items []KeyToPath `json:"items,omitempty"`
+}
+
+type ConfigMapWorkspace struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Items selects the keys of the ConfigMap that are mounted. All keys are mounted if omitted.
+	// +optional
+	Items []KeyToPath `json:"items,omitempty"`
+}
+
+type KeyToPath struct {
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Pattern=`^[^,=]+$`
+	Key string `json:"key"`
+
+	// Path is the relative path the key is mounted at. Defaults to the key.
+	// +kubebuilder:validation:Pattern=`^[^,=]*$`
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+type ProjectedWorkspace struct {
+	// +kubebuilder:validation:MinItems=1
+	Sources []ProjectedSource `json:"sources"`
+}
+
+// ProjectedSource is a Secret or a ConfigMap that is projected into the volume.
+// +kubebuilder:validation:XValidation:rule="has(self.secret) != has(self.configMap)",message="exactly one of secret and configMap must be set"
+type ProjectedSource struct {
+	// +optional
+	Secret *LocalObjectReference `json:"secret,omitempty"`
+
+	// +optional
+	ConfigMap *LocalObjectReference `json:"configMap,omitempty"`
+}
+
+type CSIWorkspace struct {
+	// Driver is the name of the CSI driver that provides the volume.
+	// +kubebuilder:validation:MinLength=1
+	Driver string `json:"driver"`
+
+	// +optional
+	ReadOnly *bool `json:"readOnly,omitempty"`
+
+	// VolumeAttributes are passed to the CSI driver.
+	// +optional
+	VolumeAttributes map[string]string `json:"volumeAttributes,omitempty"`
+}
+
+type EmptyDirWorkspace struct{}
+
+type PersistentVolumeClaimWorkspace struct {
+	// +kubebuilder:validation:MinLength=1
+	ClaimName string `json:"claimName"`
+}
+
+type VolumeClaimTemplateWorkspace struct {
+	// +optional
+	StorageClassName string `json:"storageClassName,omitempty"`
+
+	// Size is the requested storage of the PersistentVolumeClaim. Defaults to 1Gi.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// AccessModes of the PersistentVolumeClaim. Defaults to ReadWriteOnce.
+	// +kubebuilder:validation:items:Enum=ReadWriteOnce;ReadOnlyMany;ReadWriteMany;ReadWriteOncePod
+	// +optional
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+}
+
+type BuildWindowSpec struct {
+	// Windows PipelineRuns may be created in, e.g. "mon-fri 22:00-06:00" or "sat,sun 00:00-24:00". Windows whose end
+	// is before their start cross midnight.
+	// +kubebuilder:validation:MinItems=1
+	Windows []string `json:"windows"`
+
+	// TimeZone is the IANA time zone the windows are evaluated in. Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+type ScheduleSpec struct {
+	// Interval is the interval the repository is polled in, e.g. "5m" or "24h".
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// JitterPercent is the jitter in percent that is applied to the interval.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	JitterPercent *int `json:"jitterPercent,omitempty"`
+
+	// ActiveHours restricts polling to the given hours of the day.
+	// +optional
+	ActiveHours *ActiveHours `json:"activeHours,omitempty"`
+
+	// Cron is a cron expression in the standard five field format, e.g. "0 6 * * mon-fri". It takes precedence over
+	// the interval, jitter and active hours.
+	// +optional
+	Cron string `json:"cron,omitempty"`
+}
+
+// +kubebuilder:validation:XValidation:rule="self.from < self.to",message="from must be < to"
+type ActiveHours struct {
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=23
+	From int `json:"from"`
+
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=23
+	To int `json:"to"`
+}
+
+type LocalObjectReference struct {
+	Name string `json:"name"`
+}
+
+type SecretKeyReference struct {
+	Name string `json:"name"`
+
+	// +kubebuilder:default:=token
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// BuildTarget is the kind of the resources the pipeline names of a Repository refer to.
+type BuildTarget string
+
+const (
+	// BuildTargetPipeline creates PipelineRuns referencing the Pipeline.
+	BuildTargetPipeline BuildTarget = "Pipeline"
+	// BuildTargetTask creates TaskRuns referencing the Task.
+	BuildTargetTask BuildTarget = "Task"
+	// BuildTargetPipelineSpec creates PipelineRuns with the inline pipelineSpec stored in the key
+	// PipelineSpecConfigMapKey of the ConfigMap.
+	BuildTargetPipelineSpec BuildTarget = "PipelineSpec"
+
+	PipelineSpecConfigMapKey = "pipelineSpec"
+)
+
+// RunKind is the kind of a run that has been created for a release.
+type RunKind string
+
+const (
+	RunKindPipelineRun RunKind = "PipelineRun"
+	RunKindTaskRun     RunKind = "TaskRun"
+)
+
+// PipelineResolver references the Pipelines using a Tekton remote resolver instead of by name in the namespace of the
+// Repository.
+type PipelineResolver struct {
+	// +kubebuilder:validation:Enum=git;bundles;hub;cluster
+	Resolver string `json:"resolver"`
+
+	// Params are passed to the resolver. The pipeline name of the artifact type is passed as "pathInRepo" to the git
+	// resolver and as "name" to all other resolvers, unless set here. All resolvers but git also get the param "kind",
+	// which defaults to "task" for the build target Task and to "pipeline" otherwise.
+	// +optional
+	Params map[string]string `json:"params,omitempty"`
+}
+
+type ParamType string
+
+const (
+	ParamTypeString ParamType = "string"
+	ParamTypeArray  ParamType = "array"
+	ParamTypeObject ParamType = "object"
+)
+
+// Param is passed to the PipelineRuns in addition to the default params. Its values are Go templates that are
+// rendered using the data of the release, e.g. "{{ .Version }}".
+type Param struct {
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Enum=string;array;object
+	// +kubebuilder:default:=string
+	// +optional
+	Type ParamType `json:"type,omitempty"`
+
+	// Value is the template of a string param.
+	// +optional
+	Value string `json:"value,omitempty"`
+
+	// Values are the templates of the items of an array param.
+	// +optional
+	Values []string `json:"values,omitempty"`
+
+	// Properties are the templates of the keys of an object param.
+	// +optional
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type ProviderSpec struct {
+	// +kubebuilder:validation:Enum=github;gitea
+	// +kubebuilder:default:=github
+	Type ProviderType `json:"type"`
+
+	// URL is the base URL of the instance, e.g. "https://codeberg.org". Required for Gitea/Forgejo.
+	// +optional
+	URL string `json:"url,omitempty"`
+}
+
+type VersionFilterSpec struct {
+	// +kubebuilder:validation:Enum=semver
+	Impl string `json:"impl"`
+	Arg  string `json:"arg"`
+}
+
+// RepositoryStatus defines the observed state of Repository.
+type RepositoryStatus struct {
+	Ready bool `json:"ready"`
+
+	// Releases lists the releases that have been checked for missing artifacts.
+	// +listType=map
+	// +listMapKey=tag
+	// +optional
+	Releases []ReleaseStatus `json:"releases,omitempty"`
+
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// LastCheck is the time the repository has been checked the last time.
+	// +optional
+	LastCheck *metav1.Time `json:"lastCheck,omitempty"`
+
+	// LastFullCheck is the time releases and artifacts have been fetched the last time.
+	// +optional
+	LastFullCheck *metav1.Time `json:"lastFullCheck,omitempty"`
+
+	// ObservedGeneration is the generation of the spec that has been used for the last full check.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// EffectiveSpec is the spec after merging the referenced BuildProfile.
+	// +optional
+	EffectiveSpec *RepositorySpec `json:"effectiveSpec,omitempty"`
+
+	// PollInterval is the interval learned from the release cadence of the repository.
+	// +optional
+	PollInterval *metav1.Duration `json:"pollInterval,omitempty"`
+}
+
+type ReleaseStatus struct {
+	Tag string `json:"tag"`
+
+	// PublishedAt is the time the release has been published.
+	// +optional
+	PublishedAt *metav1.Time `json:"publishedAt,omitempty"`
+
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Artifacts []ArtifactStatus `json:"artifacts,omitempty"`
+}
+
+type ArtifactStatus struct {
+	Type ArtifactType `json:"type"`
+
+	// Missing is true if the artifact has not been found for the release.
+	Missing bool `json:"missing"`
+
+	// LastPipelineRun is the most recent PipelineRun that has been created to build the artifact.
+	// +optional
+	LastPipelineRun *PipelineRunStatus `json:"lastPipelineRun,omitempty"`
+}
+
+type PipelineRunStatus struct {
+	Name              string      `json:"name"`
+	CreationTimestamp metav1.Time `json:"timestamp,omitempty"`
+	RunsCreated       int         `json:"runsCreated"`
+
+	// Kind is the kind of the run. Defaults to PipelineRun.
+	// +optional
+	Kind RunKind `json:"kind,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Owner",type=string,JSONPath=`.spec.owner`
+// +kubebuilder:printcolumn:name="Repo",type=string,JSONPath=`.spec.repo`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.status)].status`
+
+// Repository is the Schema for the repositories API.
+type Repository struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RepositorySpec   `json:"spec,omitempty"`
+	Status RepositoryStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RepositoryList contains a list of Repository.
+type RepositoryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Repository `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Repository{}, &RepositoryList{})
+}
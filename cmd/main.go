@@ -1,13 +1,20 @@
 package main
 
 import (
+	"cmp"
+	"context"
 	"crypto/tls"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
+	"github.com/soerenschneider/gollum/internal/gitea"
 	"github.com/soerenschneider/gollum/internal/github"
+	"github.com/soerenschneider/gollum/internal/receiver"
 	"github.com/soerenschneider/gollum/internal/requeue"
 	"github.com/soerenschneider/gollum/internal/tekton"
 	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
@@ -16,10 +23,13 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -27,12 +37,15 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+	gollumv1beta1 "github.com/soerenschneider/gollum/api/v1beta1"
 	"github.com/soerenschneider/gollum/internal/controller"
+	webhookgollumv1alpha1 "github.com/soerenschneider/gollum/internal/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
 const (
 	defaultRequeueIntervalMin         = 60
+	defaultRequeueWindows             = "08:00-22:00"
 	defaultJitterPercentage   float64 = 20
 )
 
@@ -45,12 +58,31 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(gollumv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(gollumv1beta1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
 func main() {
 	var githubToken string
+	var githubTokenFile string
+	var githubAppId int64
+	var githubRateLimitReserve int
+	var githubHourlyBudget int
+	var githubDetectChanges bool
+	var adaptivePollingMinIntervalMin int
+	var adaptivePollingMaxIntervalMin int
+	var githubAppPrivateKeyFile string
+	var giteaToken string
 	var requeueIntervalMin int
+	var requeueWindows string
+	var requeueTimeZone string
+	var requeueBlackoutCalendar string
+	var buildWindows string
+	var webhookReceiverAddr string
+	var webhookSecret string
+	var webhookSecretKey string
+	var buildTimeZone string
+	var buildBlackoutCalendar string
 	var jitterPercentage float64
 	var metricsAddr string
 	var enableLeaderElection bool
@@ -59,9 +91,47 @@ func main() {
 	var enableHTTP2 bool
 	var verboseLogging bool
 	var tlsOpts []func(*tls.Config)
-	flag.StringVar(&githubToken, "github-token", "", "The GitHub token to use for API calls.")
+	flag.StringVar(&githubToken, "github-token", "",
+		"The GitHub token to use for API calls. Multiple tokens can be supplied separated by commas to spread the rate limit.")
+	flag.StringVar(&githubTokenFile, "github-token-file", "",
+		"Path to a file containing the GitHub token, e.g. mounted from a Secret. Changes are picked up without restart.")
+	flag.IntVar(&githubRateLimitReserve, "github-rate-limit-reserve", 0,
+		"Amount of GitHub requests per token that are reserved for urgent work. Non-urgent work, such as re-checking known "+
+			"releases, is deferred until the rate limit resets once less requests remain. 0 disables throttling.")
+	flag.IntVar(&githubHourlyBudget, "github-hourly-budget", 0,
+		"The amount of GitHub API requests per hour that may be used for polling. If set, the poll interval is derived "+
+			"from the number of repositories and their estimated cost. Disabled if 0.")
+	flag.BoolVar(&githubDetectChanges, "github-detect-changes", false,
+		"Poll the events of GitHub repositories and only fetch releases and artifacts once a release related event "+
+			"shows up. Idle repositories then cost almost no quota.")
+	flag.Int64Var(&githubAppId, "github-app-id", 0, "The ID of the GitHub App to authenticate as instead of using a token.")
+	flag.StringVar(&githubAppPrivateKeyFile, "github-app-private-key-file", "",
+		"Path to the PEM encoded private key of the GitHub App, e.g. mounted from a Secret.")
+	flag.StringVar(&giteaToken, "gitea-token", "", "The token to use for API calls to Gitea/Forgejo instances.")
 	flag.IntVar(&requeueIntervalMin, "requeue-interval", defaultRequeueIntervalMin,
 		"The interval in minutes after which repositories are requeued.")
+	flag.IntVar(&adaptivePollingMinIntervalMin, "adaptive-polling-min-interval", 15,
+		"The interval in minutes repositories are polled in around the time a release is expected.")
+	flag.IntVar(&adaptivePollingMaxIntervalMin, "adaptive-polling-max-interval", 0,
+		"The interval in minutes dormant repositories are polled in at most. If set, the poll interval of each repository "+
+			"is learned from its release cadence. Disabled if 0.")
+	flag.StringVar(&requeueWindows, "requeue-windows", defaultRequeueWindows,
+		"The windows repositories are polled in, separated by semicolons, e.g. 'mon-fri 08:00-22:00;sat,sun 10:00-14:00'. "+
+			"Windows whose end is before their start cross midnight.")
+	flag.StringVar(&requeueTimeZone, "requeue-timezone", "UTC", "The IANA time zone the requeue windows are evaluated in.")
+	flag.StringVar(&requeueBlackoutCalendar, "requeue-blackout-calendar", "",
+		"Path to an iCalendar file whose events mark periods in which no repositories are polled, e.g. holidays.")
+	flag.StringVar(&buildWindows, "build-windows", "",
+		"The windows PipelineRuns may be created in, separated by semicolons, e.g. 'mon-fri 22:00-06:00;sat,sun 00:00-24:00'. "+
+			"Missing artifacts are still detected outside the windows. Repositories may configure their own build window.")
+	flag.StringVar(&buildTimeZone, "build-timezone", "UTC", "The IANA time zone the build windows are evaluated in.")
+	flag.StringVar(&buildBlackoutCalendar, "build-blackout-calendar", "",
+		"Path to an iCalendar file whose events mark periods in which no PipelineRuns are created, e.g. change freezes.")
+	flag.StringVar(&webhookReceiverAddr, "webhook-receiver-bind-address", "",
+		"The address the GitHub webhook receiver binds to, e.g. ':9443'. Disabled if empty.")
+	flag.StringVar(&webhookSecret, "webhook-secret", "",
+		"The Secret holding the secret GitHub webhook payloads are signed with, in the form 'namespace/name'.")
+	flag.StringVar(&webhookSecretKey, "webhook-secret-key", "secret", "The key of the webhook secret within the Secret.")
 	flag.Float64Var(&jitterPercentage, "jitter", defaultJitterPercentage, "The jitter for requeuing in percent.")
 	flag.BoolVar(&verboseLogging, "verbose-logging", false, "Use verbose logging.")
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
@@ -147,20 +217,48 @@ func main() {
 		os.Exit(1)
 	}
 
-	if githubToken == "" {
-		githubToken = os.Getenv("GH_TOKEN")
-		if githubToken == "" {
-			setupLog.Info("Using GitHub token from env variable GH_TOKEN")
-		}
-	} else {
-		setupLog.Info("WARNING, no GitHub supplied, you may be running into GitHub API quota issues")
+	httpClient := retryablehttp.NewClient()
+	tokenSources, err := buildGithubTokenSources(httpClient.HTTPClient, githubToken, githubTokenFile, githubAppId, githubAppPrivateKeyFile)
+	if err != nil {
+		setupLog.Error(err, "unable to build github authentication")
+		os.Exit(1)
 	}
 
-	httpClient := retryablehttp.NewClient()
-	var githubClient controller.GithubClient
-	githubClient, err = github.NewGithubClient(httpClient.HTTPClient, &githubToken)
+	githubClient, err := github.NewGithubClient(httpClient.HTTPClient, tokenSources...)
 	if err != nil {
 		setupLog.Error(err, "unable to initialize github client")
+		os.Exit(1)
+	}
+	githubClient.SetRateLimitReserve(githubRateLimitReserve)
+
+	if githubTokenFile != "" && githubAppId <= 0 && githubAppPrivateKeyFile == "" {
+		tokenFileWatcher, err := github.NewTokenFileWatcher(githubTokenFile, githubClient)
+		if err != nil {
+			setupLog.Error(err, "unable to build github token file watcher")
+			os.Exit(1)
+		}
+		if err := mgr.Add(tokenFileWatcher); err != nil {
+			setupLog.Error(err, "unable to add github token file watcher")
+			os.Exit(1)
+		}
+	}
+
+	if giteaToken == "" {
+		giteaToken = os.Getenv("GITEA_TOKEN")
+	}
+
+	githubClientFactory := func(token string) (controller.GithubClient, error) {
+		client, err := github.NewGithubClient(httpClient.HTTPClient, github.NewStaticTokenSource(token))
+		if err != nil {
+			return nil, err
+		}
+		client.SetRateLimitReserve(githubRateLimitReserve)
+		return client, nil
+	}
+
+	giteaClientFactory := func(baseUrl string, token string) (controller.GithubClient, error) {
+		token = cmp.Or(token, giteaToken)
+		return gitea.NewGiteaClient(httpClient.HTTPClient, baseUrl, &token)
 	}
 
 	tektonClient, err := versioned.NewForConfig(mgr.GetConfig())
@@ -175,24 +273,95 @@ func main() {
 		os.Exit(1)
 	}
 
-	workdayRequeue, err := requeue.NewOnHoursRequeue(8, 22)
+	calendarRequeue, err := buildCalendarRequeue(requeueWindows, requeueTimeZone, requeueBlackoutCalendar)
 	if err != nil {
-		setupLog.Error(err, "unable to build workday requeuer")
+		setupLog.Error(err, "unable to build calendar requeuer")
 		os.Exit(1)
 	}
 
-	if err = (&controller.RepositoryReconciler{
+	var buildWindow controller.BuildWindow
+	if buildWindows != "" || buildBlackoutCalendar != "" {
+		buildWindow, err = buildCalendarRequeue(buildWindows, buildTimeZone, buildBlackoutCalendar)
+		if err != nil {
+			setupLog.Error(err, "unable to build build window")
+			os.Exit(1)
+		}
+	}
+
+	var scheduler controller.Scheduler
+	if githubHourlyBudget > 0 {
+		scheduler, err = requeue.NewQuotaScheduler(githubHourlyBudget, time.Minute*time.Duration(requeueIntervalMin))
+		if err != nil {
+			setupLog.Error(err, "unable to build quota scheduler")
+			os.Exit(1)
+		}
+	}
+
+	var cadence controller.CadenceRequeue
+	if adaptivePollingMaxIntervalMin > 0 {
+		cadence, err = requeue.NewCadenceRequeue(time.Minute*time.Duration(adaptivePollingMinIntervalMin), time.Minute*time.Duration(adaptivePollingMaxIntervalMin))
+		if err != nil {
+			setupLog.Error(err, "unable to build adaptive polling")
+			os.Exit(1)
+		}
+	}
+
+	repositoryReconciler := &controller.RepositoryReconciler{
 		Client:                 mgr.GetClient(),
 		Scheme:                 mgr.GetScheme(),
+		APIReader:              mgr.GetAPIReader(),
 		GithubClient:           githubClient,
+		GithubClientFactory:    githubClientFactory,
+		GiteaClientFactory:     giteaClientFactory,
 		PipelineRunner:         pipelineRunner,
-		Requeue:                workdayRequeue,
+		Requeue:                calendarRequeue,
+		Scheduler:              scheduler,
+		Cadence:                cadence,
+		BuildWindow:            buildWindow,
+		DetectChanges:          githubDetectChanges,
 		DefaultRequeueInterval: time.Minute * time.Duration(requeueIntervalMin),
 		DefaultJitterPercent:   jitterPercentage,
-	}).SetupWithManager(mgr); err != nil {
+	}
+	if err = repositoryReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Repository")
 		os.Exit(1)
 	}
+
+	if err = (&controller.RepositoryDiscoveryReconciler{
+		Client:           mgr.GetClient(),
+		Scheme:           mgr.GetScheme(),
+		RepositoryLister: githubClient,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RepositoryDiscovery")
+		os.Exit(1)
+	}
+
+	if err = (&controller.RepositorySetReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RepositorySet")
+		os.Exit(1)
+	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = webhookgollumv1alpha1.SetupRepositoryWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Repository")
+			os.Exit(1)
+		}
+	}
+
+	if webhookReceiverAddr != "" {
+		webhookReceiver, err := buildWebhookReceiver(mgr.GetAPIReader(), webhookReceiverAddr, webhookSecret, webhookSecretKey, repositoryReconciler)
+		if err != nil {
+			setupLog.Error(err, "unable to build webhook receiver")
+			os.Exit(1)
+		}
+		if err := mgr.Add(webhookReceiver); err != nil {
+			setupLog.Error(err, "unable to add webhook receiver")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -210,3 +379,91 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+func buildGithubTokenSources(httpClient *http.Client, token, tokenFile string, appId int64, privateKeyFile string) ([]github.TokenSource, error) {
+	if appId > 0 || privateKeyFile != "" {
+		privateKey, err := os.ReadFile(privateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read private key of GitHub App: %w", err)
+		}
+		setupLog.Info("Authenticating as GitHub App", "app_id", appId)
+		appTokenSource, err := github.NewAppTokenSource(httpClient, appId, privateKey)
+		if err != nil {
+			return nil, err
+		}
+		return []github.TokenSource{appTokenSource}, nil
+	}
+
+	if tokenFile != "" {
+		token, err := github.ReadTokenFile(tokenFile)
+		if err != nil {
+			return nil, err
+		}
+		setupLog.Info("Using GitHub token from file", "path", tokenFile)
+		return []github.TokenSource{github.NewStaticTokenSource(token)}, nil
+	}
+
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+		if token != "" {
+			setupLog.Info("Using GitHub token from env variable GH_TOKEN")
+		}
+	}
+
+	var ret []github.TokenSource
+	for _, t := range strings.Split(token, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			ret = append(ret, github.NewStaticTokenSource(t))
+		}
+	}
+
+	if len(ret) == 0 {
+		setupLog.Info("WARNING, no GitHub token supplied, you may be running into GitHub API quota issues")
+	} else if len(ret) > 1 {
+		setupLog.Info("Using pool of GitHub tokens", "tokens", len(ret))
+	}
+
+	return ret, nil
+}
+
+func buildCalendarRequeue(windowsSpec, timeZone, blackoutCalendar string) (*requeue.CalendarRequeue, error) {
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", timeZone, err)
+	}
+
+	windows, err := requeue.ParseWindows(windowsSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	var blackouts []requeue.Blackout
+	if blackoutCalendar != "" {
+		blackouts, err = requeue.ReadBlackoutsFromFile(blackoutCalendar, location)
+		if err != nil {
+			return nil, fmt.Errorf("could not read blackout calendar: %w", err)
+		}
+		setupLog.Info("Read blackout calendar", "path", blackoutCalendar, "blackouts", len(blackouts))
+	}
+
+	return requeue.NewCalendarRequeue(location, windows, blackouts)
+}
+
+// buildWebhookReceiver builds the receiver for GitHub webhooks. The webhook secret is read using an uncached reader, so
+// no informer for all Secrets of the cluster is started.
+func buildWebhookReceiver(c client.Reader, addr, secretRef, secretKey string, trigger receiver.Trigger) (*receiver.WebhookReceiver, error) {
+	namespace, name, found := strings.Cut(secretRef, "/")
+	if !found || namespace == "" || name == "" {
+		return nil, fmt.Errorf("invalid webhook secret %q, expected 'namespace/name'", secretRef)
+	}
+
+	secretSource := func(ctx context.Context) ([]byte, error) {
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, secret); err != nil {
+			return nil, err
+		}
+		return secret.Data[secretKey], nil
+	}
+
+	return receiver.NewWebhookReceiver(addr, secretSource, trigger)
+}
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const buildProfileRefField = ".spec.buildProfileRef.name"
+
+// applyBuildProfile merges the BuildProfile referenced by the repository into its spec. The merged spec is only kept
+// in memory and shown in the status.
+func (r *RepositoryReconciler) applyBuildProfile(ctx context.Context, data *gollumv1alpha1.Repository) error {
+	ref := data.Spec.BuildProfileRef
+	if ref == nil || ref.Name == "" {
+		data.Status.EffectiveSpec = nil
+		return nil
+	}
+
+	profile := &gollumv1alpha1.BuildProfile{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: data.Namespace, Name: ref.Name}, profile); err != nil {
+		return fmt.Errorf("could not get build profile %q: %w", ref.Name, err)
+	}
+
+	mergeBuildProfile(&data.Spec, profile.Spec)
+	data.Status.EffectiveSpec = data.Spec.DeepCopy()
+	return nil
+}
+
+// mergeBuildProfile fills the fields of the spec that are not set with the values of the profile. Pipelines,
+// workspaces and params are merged by name, the spec taking precedence.
+func mergeBuildProfile(spec *gollumv1alpha1.RepositorySpec, profile gollumv1alpha1.BuildProfileSpec) {
+	if spec.CloneUsingSsh == nil && profile.CloneUsingSsh != nil {
+		cloneUsingSsh := *profile.CloneUsingSsh
+		spec.CloneUsingSsh = &cloneUsingSsh
+	}
+
+	spec.PipelineRunName = cmp.Or(spec.PipelineRunName, profile.PipelineRunName)
+	spec.BuildTarget = cmp.Or(spec.BuildTarget, profile.BuildTarget)
+
+	if spec.VersionFilter == nil {
+		spec.VersionFilter = profile.VersionFilter.DeepCopy()
+	}
+
+	if spec.PipelineResolver == nil {
+		spec.PipelineResolver = profile.PipelineResolver.DeepCopy()
+	}
+
+	if spec.PipelineRunTemplate == nil {
+		spec.PipelineRunTemplate = profile.PipelineRunTemplate.DeepCopy()
+	}
+
+	for _, param := range profile.Params {
+		if !slices.ContainsFunc(spec.Params, func(p gollumv1alpha1.Param) bool { return p.Name == param.Name }) {
+			spec.Params = append(spec.Params, *param.DeepCopy())
+		}
+	}
+
+	if len(profile.PipelineNames) > 0 {
+		pipelineNames := maps.Clone(profile.PipelineNames)
+		maps.Copy(pipelineNames, spec.PipelineNames)
+		spec.PipelineNames = pipelineNames
+	}
+
+	if len(profile.Workspaces) > 0 {
+		workspaces := make(map[string]map[string]string, len(profile.Workspaces))
+		for name, workspace := range profile.Workspaces {
+			workspaces[name] = maps.Clone(workspace)
+		}
+		maps.Copy(workspaces, spec.Workspaces)
+		spec.Workspaces = workspaces
+	}
+}
+
+func (r *RepositoryReconciler) findRepositoriesForBuildProfile(ctx context.Context, profile client.Object) []reconcile.Request {
+	repos := &gollumv1alpha1.RepositoryList{}
+	if err := r.List(ctx, repos, client.InNamespace(profile.GetNamespace()), client.MatchingFields{buildProfileRefField: profile.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "could not list repositories for build profile", "buildprofile", profile.GetName())
+		return nil
+	}
+
+	ret := make([]reconcile.Request, 0, len(repos.Items))
+	for _, repo := range repos.Items {
+		ret = append(ret, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: repo.Namespace, Name: repo.Name}})
+	}
+	return ret
+}
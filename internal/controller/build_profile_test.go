@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+)
+
+func TestMergeBuildProfile(t *testing.T) {
+	enabled, disabled := true, false
+
+	tests := []struct {
+		name    string
+		spec    gollumv1alpha1.RepositorySpec
+		profile gollumv1alpha1.BuildProfileSpec
+		want    gollumv1alpha1.RepositorySpec
+	}{
+		{
+			name: "empty profile",
+			spec: gollumv1alpha1.RepositorySpec{Owner: "owner", Repository: "repo"},
+			want: gollumv1alpha1.RepositorySpec{Owner: "owner", Repository: "repo"},
+		},
+		{
+			name: "unset fields are filled",
+			profile: gollumv1alpha1.BuildProfileSpec{
+				CloneUsingSsh:    &enabled,
+				PipelineRunName:  "build",
+				BuildTarget:      gollumv1alpha1.BuildTargetTask,
+				PipelineResolver: &gollumv1alpha1.PipelineResolver{Resolver: "git"},
+			},
+			want: func() gollumv1alpha1.RepositorySpec {
+				ret := gollumv1alpha1.RepositorySpec{}
+				ret.CloneUsingSsh = &enabled
+				ret.PipelineRunName = "build"
+				ret.BuildTarget = gollumv1alpha1.BuildTargetTask
+				ret.PipelineResolver = &gollumv1alpha1.PipelineResolver{Resolver: "git"}
+				return ret
+			}(),
+		},
+		{
+			name: "spec takes precedence",
+			spec: func() gollumv1alpha1.RepositorySpec {
+				ret := gollumv1alpha1.RepositorySpec{}
+				ret.CloneUsingSsh = &disabled
+				ret.PipelineRunName = "custom"
+				ret.PipelineResolver = &gollumv1alpha1.PipelineResolver{Resolver: "bundles"}
+				return ret
+			}(),
+			profile: gollumv1alpha1.BuildProfileSpec{
+				CloneUsingSsh:    &enabled,
+				PipelineRunName:  "build",
+				PipelineResolver: &gollumv1alpha1.PipelineResolver{Resolver: "git"},
+			},
+			want: func() gollumv1alpha1.RepositorySpec {
+				ret := gollumv1alpha1.RepositorySpec{}
+				ret.CloneUsingSsh = &disabled
+				ret.PipelineRunName = "custom"
+				ret.PipelineResolver = &gollumv1alpha1.PipelineResolver{Resolver: "bundles"}
+				return ret
+			}(),
+		},
+		{
+			name: "pipelines, workspaces and params are merged by name",
+			spec: func() gollumv1alpha1.RepositorySpec {
+				ret := gollumv1alpha1.RepositorySpec{}
+				ret.PipelineNames = map[gollumv1alpha1.ArtifactType]string{gollumv1alpha1.ArtifactsKeyReleaseAssets: "custom"}
+				ret.Workspaces = map[string]map[string]string{"source": {"type": "emptyDir"}}
+				ret.Params = []gollumv1alpha1.Param{{Name: "registry", Value: "custom"}}
+				return ret
+			}(),
+			profile: gollumv1alpha1.BuildProfileSpec{
+				PipelineNames: map[gollumv1alpha1.ArtifactType]string{
+					gollumv1alpha1.ArtifactsKeyReleaseAssets:     "build",
+					gollumv1alpha1.ArtifactsKeyPackagesContainer: "container",
+				},
+				Workspaces: map[string]map[string]string{
+					"source": {"type": "pvc"},
+					"cache":  {"type": "pvc"},
+				},
+				Params: []gollumv1alpha1.Param{{Name: "registry", Value: "ghcr.io"}, {Name: "platform", Value: "linux/amd64"}},
+			},
+			want: func() gollumv1alpha1.RepositorySpec {
+				ret := gollumv1alpha1.RepositorySpec{}
+				ret.PipelineNames = map[gollumv1alpha1.ArtifactType]string{
+					gollumv1alpha1.ArtifactsKeyReleaseAssets:     "custom",
+					gollumv1alpha1.ArtifactsKeyPackagesContainer: "container",
+				}
+				ret.Workspaces = map[string]map[string]string{
+					"source": {"type": "emptyDir"},
+					"cache":  {"type": "pvc"},
+				}
+				ret.Params = []gollumv1alpha1.Param{{Name: "registry", Value: "custom"}, {Name: "platform", Value: "linux/amd64"}}
+				return ret
+			}(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mergeBuildProfile(&tt.spec, tt.profile)
+			if !reflect.DeepEqual(tt.spec, tt.want) {
+				t.Errorf("mergeBuildProfile() = %+v, want %+v", tt.spec, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeBuildProfile_DoesNotShareProfile(t *testing.T) {
+	profile := gollumv1alpha1.BuildProfileSpec{
+		Workspaces:       map[string]map[string]string{"source": {"type": "pvc"}},
+		PipelineResolver: &gollumv1alpha1.PipelineResolver{Resolver: "git", Params: map[string]string{"revision": "main"}},
+	}
+
+	spec := gollumv1alpha1.RepositorySpec{}
+	mergeBuildProfile(&spec, profile)
+	spec.Workspaces["source"]["type"] = "emptyDir"
+	spec.PipelineResolver.Params["revision"] = "v1"
+
+	if profile.Workspaces["source"]["type"] != "pvc" || profile.PipelineResolver.Params["revision"] != "main" {
+		t.Errorf("expected profile to be unchanged, got %+v", profile)
+	}
+}
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+	"github.com/soerenschneider/gollum/internal/tekton"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+var ErrPipelineSpecNotFound = errors.New("configmap holding the pipelineSpec not found")
+
+// runState is the state of a PipelineRun or TaskRun that has been created for a release.
+type runState struct {
+	creationTimestamp time.Time
+	startTime         *metav1.Time
+	completionTime    *metav1.Time
+	succeeded         bool
+}
+
+// getBuildTargetSpec returns the declarations of the Pipeline, Task or inline pipelineSpec the name refers to.
+func (r *RepositoryReconciler) getBuildTargetSpec(ctx context.Context, target gollumv1alpha1.BuildTarget, namespace, name string) (*pipelinev1.PipelineSpec, error) {
+	switch target {
+	case gollumv1alpha1.BuildTargetTask:
+		task, err := r.PipelineRunner.GetTask(ctx, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		return tekton.DeclarationsOfTask(task.Spec), nil
+	case gollumv1alpha1.BuildTargetPipelineSpec:
+		configMap := &v1.ConfigMap{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, configMap); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("%w: %s", ErrPipelineSpecNotFound, name)
+			}
+			return nil, err
+		}
+
+		data, found := configMap.Data[gollumv1alpha1.PipelineSpecConfigMapKey]
+		if !found {
+			return nil, fmt.Errorf("%w: key %q missing in %s", ErrPipelineSpecNotFound, gollumv1alpha1.PipelineSpecConfigMapKey, name)
+		}
+
+		spec, err := tekton.ParsePipelineSpec(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pipelineSpec in configmap %s: %w", name, err)
+		}
+		return spec, nil
+	default:
+		pipeline, err := r.PipelineRunner.GetPipeline(ctx, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		return &pipeline.Spec, nil
+	}
+}
+
+// getRunState fetches the PipelineRun or TaskRun the status refers to.
+func (r *RepositoryReconciler) getRunState(ctx context.Context, namespace string, run *gollumv1alpha1.PipelineRun) (*runState, error) {
+	if run.Kind == gollumv1alpha1.RunKindTaskRun {
+		taskRun, err := r.PipelineRunner.GetTaskRun(ctx, namespace, run.Name)
+		if err != nil {
+			return nil, err
+		}
+		return newRunState(taskRun.ObjectMeta, taskRun.Status.Status, taskRun.Status.StartTime, taskRun.Status.CompletionTime), nil
+	}
+
+	pipelineRun, err := r.PipelineRunner.GetPipelineRun(ctx, namespace, run.Name)
+	if err != nil {
+		return nil, err
+	}
+	return newRunState(pipelineRun.ObjectMeta, pipelineRun.Status.Status, pipelineRun.Status.StartTime, pipelineRun.Status.CompletionTime), nil
+}
+
+func newRunState(meta metav1.ObjectMeta, status duckv1.Status, startTime, completionTime *metav1.Time) *runState {
+	state := &runState{
+		creationTimestamp: meta.CreationTimestamp.Time,
+		startTime:         startTime,
+		completionTime:    completionTime,
+	}
+
+	for _, condition := range status.Conditions {
+		if condition.Type == apis.ConditionSucceeded && condition.IsTrue() {
+			state.succeeded = true
+		}
+	}
+
+	return state
+}
+
+func isRunNotFound(err error) bool {
+	return errors.Is(err, tekton.ErrTektonPipelineNotFound) || errors.Is(err, tekton.ErrTektonTaskNotFound)
+}
+
+// startRun creates a TaskRun or PipelineRun, depending on the build target of the request.
+func (r *RepositoryReconciler) startRun(ctx context.Context, req tekton.CreatePipelineRunRequest) (string, gollumv1alpha1.RunKind, error) {
+	if req.Target == gollumv1alpha1.BuildTargetTask {
+		run, err := r.PipelineRunner.CreateTaskRun(ctx, req)
+		if err != nil {
+			return "", "", err
+		}
+		return run.Name, gollumv1alpha1.RunKindTaskRun, nil
+	}
+
+	run, err := r.PipelineRunner.CreatePipelineRun(ctx, req)
+	if err != nil {
+		return "", "", err
+	}
+	return run.Name, gollumv1alpha1.RunKindPipelineRun, nil
+}
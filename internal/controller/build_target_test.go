@@ -0,0 +1,409 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+	"github.com/soerenschneider/gollum/internal/tekton"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+// fakePipelineRunner serves Pipelines, Tasks and their runs from memory and records the runs it creates.
+type fakePipelineRunner struct {
+	pipelines    map[string]*pipelinev1.Pipeline
+	tasks        map[string]*pipelinev1.Task
+	pipelineRuns map[string]*pipelinev1.PipelineRun
+	taskRuns     map[string]*pipelinev1.TaskRun
+	created      []tekton.CreatePipelineRunRequest
+	createErr    error
+}
+
+func (f *fakePipelineRunner) GetPipeline(_ context.Context, _, name string) (*pipelinev1.Pipeline, error) {
+	if pipeline, found := f.pipelines[name]; found {
+		return pipeline, nil
+	}
+	return nil, tekton.ErrTektonPipelineNotFound
+}
+
+func (f *fakePipelineRunner) GetPipelineRun(_ context.Context, _, name string) (*pipelinev1.PipelineRun, error) {
+	if run, found := f.pipelineRuns[name]; found {
+		return run, nil
+	}
+	return nil, tekton.ErrTektonPipelineNotFound
+}
+
+func (f *fakePipelineRunner) CreatePipelineRun(_ context.Context, req tekton.CreatePipelineRunRequest) (*pipelinev1.PipelineRun, error) {
+	if f.createErr != nil {
+		return nil, f.createErr
+	}
+	f.created = append(f.created, req)
+	return &pipelinev1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Namespace: req.Namespace, Name: fmt.Sprintf("%s-%d", req.PipelineRunName, len(f.created))}}, nil
+}
+
+func (f *fakePipelineRunner) GetTask(_ context.Context, _, name string) (*pipelinev1.Task, error) {
+	if task, found := f.tasks[name]; found {
+		return task, nil
+	}
+	return nil, tekton.ErrTektonTaskNotFound
+}
+
+func (f *fakePipelineRunner) GetTaskRun(_ context.Context, _, name string) (*pipelinev1.TaskRun, error) {
+	if run, found := f.taskRuns[name]; found {
+		return run, nil
+	}
+	return nil, tekton.ErrTektonTaskNotFound
+}
+
+func (f *fakePipelineRunner) CreateTaskRun(_ context.Context, req tekton.CreatePipelineRunRequest) (*pipelinev1.TaskRun, error) {
+	if f.createErr != nil {
+		return nil, f.createErr
+	}
+	f.created = append(f.created, req)
+	return &pipelinev1.TaskRun{ObjectMeta: metav1.ObjectMeta{Namespace: req.Namespace, Name: fmt.Sprintf("%s-%d", req.PipelineRunName, len(f.created))}}, nil
+}
+
+func newFakePipelineRunner() *fakePipelineRunner {
+	return &fakePipelineRunner{
+		pipelines: map[string]*pipelinev1.Pipeline{
+			"build": {Spec: pipelinev1.PipelineSpec{Params: pipelinev1.ParamSpecs{{Name: "version"}}}},
+		},
+		tasks: map[string]*pipelinev1.Task{
+			"build-task": {Spec: pipelinev1.TaskSpec{
+				Params:     pipelinev1.ParamSpecs{{Name: "tag"}},
+				Workspaces: []pipelinev1.WorkspaceDeclaration{{Name: "source", Optional: true}},
+			}},
+		},
+		pipelineRuns: map[string]*pipelinev1.PipelineRun{},
+		taskRuns:     map[string]*pipelinev1.TaskRun{},
+	}
+}
+
+func TestGetBuildTargetSpec(t *testing.T) {
+	pipelineSpecs := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "builds", Name: "inline"},
+		Data: map[string]string{
+			gollumv1alpha1.PipelineSpecConfigMapKey: "params:\n- name: commit\ntasks:\n- name: build\n  taskRef:\n    name: build-task\n",
+		},
+	}
+	emptyConfigMap := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "builds", Name: "empty"}}
+
+	reconciler := &RepositoryReconciler{
+		Client:         newIndexedClient(t, pipelineSpecs, emptyConfigMap),
+		PipelineRunner: newFakePipelineRunner(),
+	}
+
+	tests := []struct {
+		name           string
+		target         gollumv1alpha1.BuildTarget
+		targetName     string
+		wantParam      string
+		wantWorkspaces int
+		wantErr        error
+	}{
+		{
+			name:       "pipeline",
+			target:     gollumv1alpha1.BuildTargetPipeline,
+			targetName: "build",
+			wantParam:  "version",
+		},
+		{
+			name:       "pipeline is default",
+			targetName: "build",
+			wantParam:  "version",
+		},
+		{
+			name:       "missing pipeline",
+			target:     gollumv1alpha1.BuildTargetPipeline,
+			targetName: "missing",
+			wantErr:    tekton.ErrTektonPipelineNotFound,
+		},
+		{
+			name:           "task",
+			target:         gollumv1alpha1.BuildTargetTask,
+			targetName:     "build-task",
+			wantParam:      "tag",
+			wantWorkspaces: 1,
+		},
+		{
+			name:       "missing task",
+			target:     gollumv1alpha1.BuildTargetTask,
+			targetName: "missing",
+			wantErr:    tekton.ErrTektonTaskNotFound,
+		},
+		{
+			name:       "pipeline spec",
+			target:     gollumv1alpha1.BuildTargetPipelineSpec,
+			targetName: "inline",
+			wantParam:  "commit",
+		},
+		{
+			name:       "missing configmap",
+			target:     gollumv1alpha1.BuildTargetPipelineSpec,
+			targetName: "missing",
+			wantErr:    ErrPipelineSpecNotFound,
+		},
+		{
+			name:       "configmap without pipeline spec",
+			target:     gollumv1alpha1.BuildTargetPipelineSpec,
+			targetName: "empty",
+			wantErr:    ErrPipelineSpecNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reconciler.getBuildTargetSpec(context.Background(), tt.target, "builds", tt.targetName)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("getBuildTargetSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if len(got.Params) != 1 || got.Params[0].Name != tt.wantParam {
+				t.Errorf("getBuildTargetSpec() params = %v, want %q", got.Params, tt.wantParam)
+			}
+			if len(got.Workspaces) != tt.wantWorkspaces {
+				t.Errorf("getBuildTargetSpec() workspaces = %v, want %d", got.Workspaces, tt.wantWorkspaces)
+			}
+		})
+	}
+}
+
+func TestCheckIfPipelineExists_Condition(t *testing.T) {
+	reconciler := &RepositoryReconciler{
+		Client:         newIndexedClient(t),
+		PipelineRunner: newFakePipelineRunner(),
+	}
+
+	repo := &gollumv1alpha1.Repository{}
+	repo.Spec.BuildTarget = gollumv1alpha1.BuildTargetTask
+	repo.Spec.PipelineNames = map[gollumv1alpha1.ArtifactType]string{gollumv1alpha1.ArtifactsKeyReleaseAssets: "missing-task"}
+
+	if _, err := reconciler.checkIfPipelineExists(context.Background(), repo, "builds"); err == nil {
+		t.Fatal("expected error for missing task")
+	}
+
+	condition := meta.FindStatusCondition(repo.Status.Conditions, "TektonPipelineUnavailable")
+	if condition == nil {
+		t.Fatalf("expected TektonPipelineUnavailable condition, got %v", repo.Status.Conditions)
+	}
+	if condition.Reason != "TaskNotFound" {
+		t.Errorf("expected reason TaskNotFound, got %q", condition.Reason)
+	}
+	if !strings.Contains(condition.Message, tekton.ErrTektonTaskNotFound.Error()) {
+		t.Errorf("expected message to contain the error, got %q", condition.Message)
+	}
+}
+
+func TestGetRunState(t *testing.T) {
+	created := metav1.NewTime(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	started := metav1.NewTime(created.Add(time.Minute))
+	completed := metav1.NewTime(created.Add(10 * time.Minute))
+
+	runner := newFakePipelineRunner()
+	runner.taskRuns["running-task"] = &pipelinev1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created},
+		Status: pipelinev1.TaskRunStatus{
+			Status:              duckv1.Status{Conditions: duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: v1.ConditionUnknown}}},
+			TaskRunStatusFields: pipelinev1.TaskRunStatusFields{StartTime: &started},
+		},
+	}
+	runner.taskRuns["succeeded-task"] = &pipelinev1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created},
+		Status: pipelinev1.TaskRunStatus{
+			Status:              duckv1.Status{Conditions: duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue}}},
+			TaskRunStatusFields: pipelinev1.TaskRunStatusFields{StartTime: &started, CompletionTime: &completed},
+		},
+	}
+	runner.pipelineRuns["failed-pipeline"] = &pipelinev1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created},
+		Status: pipelinev1.PipelineRunStatus{
+			Status:                  duckv1.Status{Conditions: duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: v1.ConditionFalse}}},
+			PipelineRunStatusFields: pipelinev1.PipelineRunStatusFields{StartTime: &started, CompletionTime: &completed},
+		},
+	}
+	runner.pipelineRuns["succeeded-pipeline"] = &pipelinev1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created},
+		Status: pipelinev1.PipelineRunStatus{
+			Status:                  duckv1.Status{Conditions: duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue}}},
+			PipelineRunStatusFields: pipelinev1.PipelineRunStatusFields{StartTime: &started, CompletionTime: &completed},
+		},
+	}
+	reconciler := &RepositoryReconciler{PipelineRunner: runner}
+
+	tests := []struct {
+		name          string
+		run           gollumv1alpha1.PipelineRun
+		wantSucceeded bool
+		wantCompleted bool
+		wantNotFound  bool
+	}{
+		{
+			name: "running taskrun",
+			run:  gollumv1alpha1.PipelineRun{Name: "running-task", Kind: gollumv1alpha1.RunKindTaskRun},
+		},
+		{
+			name:          "succeeded taskrun",
+			run:           gollumv1alpha1.PipelineRun{Name: "succeeded-task", Kind: gollumv1alpha1.RunKindTaskRun},
+			wantSucceeded: true,
+			wantCompleted: true,
+		},
+		{
+			name:         "missing taskrun",
+			run:          gollumv1alpha1.PipelineRun{Name: "succeeded-pipeline", Kind: gollumv1alpha1.RunKindTaskRun},
+			wantNotFound: true,
+		},
+		{
+			name:          "failed pipelinerun",
+			run:           gollumv1alpha1.PipelineRun{Name: "failed-pipeline", Kind: gollumv1alpha1.RunKindPipelineRun},
+			wantCompleted: true,
+		},
+		{
+			name:          "pipelinerun is default",
+			run:           gollumv1alpha1.PipelineRun{Name: "succeeded-pipeline"},
+			wantSucceeded: true,
+			wantCompleted: true,
+		},
+		{
+			name:         "missing pipelinerun",
+			run:          gollumv1alpha1.PipelineRun{Name: "succeeded-task"},
+			wantNotFound: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reconciler.getRunState(context.Background(), "builds", &tt.run)
+			if isRunNotFound(err) != tt.wantNotFound {
+				t.Fatalf("getRunState() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+			if tt.wantNotFound {
+				return
+			}
+			if err != nil {
+				t.Fatalf("getRunState() error = %v", err)
+			}
+			if !got.creationTimestamp.Equal(created.Time) {
+				t.Errorf("getRunState() creationTimestamp = %v, want %v", got.creationTimestamp, created.Time)
+			}
+			if got.startTime == nil || !got.startTime.Equal(&started) {
+				t.Errorf("getRunState() startTime = %v, want %v", got.startTime, started)
+			}
+			if got.succeeded != tt.wantSucceeded {
+				t.Errorf("getRunState() succeeded = %v, want %v", got.succeeded, tt.wantSucceeded)
+			}
+			if (got.completionTime != nil) != tt.wantCompleted {
+				t.Errorf("getRunState() completionTime = %v, wantCompleted %v", got.completionTime, tt.wantCompleted)
+			}
+		})
+	}
+}
+
+func TestStartRun(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    gollumv1alpha1.BuildTarget
+		createErr error
+		wantKind  gollumv1alpha1.RunKind
+		wantErr   error
+	}{
+		{
+			name:     "taskrun",
+			target:   gollumv1alpha1.BuildTargetTask,
+			wantKind: gollumv1alpha1.RunKindTaskRun,
+		},
+		{
+			name:     "pipelinerun",
+			target:   gollumv1alpha1.BuildTargetPipeline,
+			wantKind: gollumv1alpha1.RunKindPipelineRun,
+		},
+		{
+			name:     "pipelinerun with inline spec",
+			target:   gollumv1alpha1.BuildTargetPipelineSpec,
+			wantKind: gollumv1alpha1.RunKindPipelineRun,
+		},
+		{
+			name:      "taskrun forbidden",
+			target:    gollumv1alpha1.BuildTargetTask,
+			createErr: tekton.ErrTektonCreateTaskRunUnauthorized,
+			wantErr:   tekton.ErrTektonCreateTaskRunUnauthorized,
+		},
+		{
+			name:      "pipelinerun forbidden",
+			target:    gollumv1alpha1.BuildTargetPipeline,
+			createErr: tekton.ErrTektonCreatePipelineRunJobUnauthorized,
+			wantErr:   tekton.ErrTektonCreatePipelineRunJobUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := newFakePipelineRunner()
+			runner.createErr = tt.createErr
+			reconciler := &RepositoryReconciler{PipelineRunner: runner}
+
+			req := tekton.CreatePipelineRunRequest{Namespace: "builds", PipelineRunName: "gollum-v1", PipelineName: "build", Target: tt.target}
+			name, kind, err := reconciler.startRun(context.Background(), req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("startRun() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(runner.created) != 0 {
+					t.Errorf("expected no run to be created, got %v", runner.created)
+				}
+				return
+			}
+			if kind != tt.wantKind {
+				t.Errorf("startRun() kind = %q, want %q", kind, tt.wantKind)
+			}
+			if name != "gollum-v1-1" {
+				t.Errorf("startRun() name = %q, want name of created run", name)
+			}
+			if len(runner.created) != 1 || runner.created[0].Target != tt.target {
+				t.Errorf("expected one run for target %q to be created, got %v", tt.target, runner.created)
+			}
+		})
+	}
+}
+
+func TestStartRun_TracksTaskRunStatus(t *testing.T) {
+	runner := newFakePipelineRunner()
+	reconciler := &RepositoryReconciler{PipelineRunner: runner}
+
+	req := tekton.CreatePipelineRunRequest{Namespace: "builds", PipelineRunName: "gollum-v1", PipelineName: "build-task", Target: gollumv1alpha1.BuildTargetTask}
+	name, kind, err := reconciler.startRun(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := &gollumv1alpha1.PipelineRun{Name: name, Kind: kind}
+
+	// the run has not been observed yet
+	if _, err := reconciler.getRunState(context.Background(), "builds", run); !isRunNotFound(err) {
+		t.Fatalf("expected run not to be found, got %v", err)
+	}
+
+	completed := metav1.Now()
+	runner.taskRuns[name] = &pipelinev1.TaskRun{
+		Status: pipelinev1.TaskRunStatus{
+			Status:              duckv1.Status{Conditions: duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue}}},
+			TaskRunStatusFields: pipelinev1.TaskRunStatusFields{CompletionTime: &completed},
+		},
+	}
+	state, err := reconciler.getRunState(context.Background(), "builds", run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state.succeeded || state.completionTime == nil {
+		t.Errorf("expected succeeded TaskRun to be tracked, got %+v", state)
+	}
+}
@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// generatedByKindLabel and generatedByNameLabel are set on generated Repositories, so the Repositories of a
+	// generator can be listed.
+	generatedByKindLabel = "gollum.soeren.cloud/generated-by-kind"
+	generatedByNameLabel = "gollum.soeren.cloud/generated-by-name"
+)
+
+var (
+	invalidNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+	plainNameChars   = regexp.MustCompile(`^[a-z0-9]+$`)
+
+	errNotControlled = errors.New("repository exists and is not controlled by the generator")
+)
+
+// getGeneratedRepositoryName returns a valid object name for the Repository that watches the given repository. Names
+// of repositories whose owner or name contain characters other than letters and digits are ambiguous, e.g. "a-b/c" and
+// "a/b-c", so a hash of the repository is appended to them.
+func getGeneratedRepositoryName(owner, repo string) string {
+	owner, repo = strings.ToLower(owner), strings.ToLower(repo)
+	if plainNameChars.MatchString(owner) && plainNameChars.MatchString(repo) {
+		return fmt.Sprintf("%s-%s", owner, repo)
+	}
+
+	ownerRepo := fmt.Sprintf("%s/%s", owner, repo)
+	hash := sha256.Sum256([]byte(ownerRepo))
+	name := strings.Trim(invalidNameChars.ReplaceAllString(ownerRepo, "-"), "-")
+	return fmt.Sprintf("%s-%s", name, hex.EncodeToString(hash[:4]))
+}
+
+// renderRepository builds the Repository for the given repository from a template.
+func renderRepository(generator client.Object, template gollumv1alpha1.RepositoryTemplate, owner, repo string) gollumv1alpha1.Repository {
+	ret := gollumv1alpha1.Repository{}
+	ret.Name = getGeneratedRepositoryName(owner, repo)
+	ret.Namespace = generator.GetNamespace()
+	ret.Labels = maps.Clone(template.Metadata.Labels)
+	ret.Annotations = maps.Clone(template.Metadata.Annotations)
+	ret.Spec.Owner = owner
+	ret.Spec.Repository = repo
+	template.Spec.DeepCopyInto(&ret.Spec.RepositoryConfig)
+	return ret
+}
+
+// reconcileGeneratedRepositories creates or updates the desired Repositories, which are owned by the generator.
+// Existing Repositories that are not controlled by the generator, e.g. written by hand, are not adopted but skipped. If
+// prune is true, Repositories of the generator that are not desired anymore are deleted. It returns the amount of
+// created or updated Repositories and the names of the skipped Repositories.
+func reconcileGeneratedRepositories(ctx context.Context, c client.Client, scheme *runtime.Scheme, generator client.Object, kind string, desired []gollumv1alpha1.Repository, prune bool) (int, []string, error) {
+	var errs error
+	var skipped []string
+	changed := 0
+	desiredNames := map[string]bool{}
+
+	for _, repo := range desired {
+		desiredNames[repo.Name] = true
+
+		obj := &gollumv1alpha1.Repository{}
+		obj.Name = repo.Name
+		obj.Namespace = repo.Namespace
+		result, err := controllerutil.CreateOrUpdate(ctx, c, obj, func() error {
+			if obj.ResourceVersion != "" && !isControlledBy(obj, generator) {
+				return errNotControlled
+			}
+
+			if obj.Labels == nil {
+				obj.Labels = map[string]string{}
+			}
+			maps.Copy(obj.Labels, repo.Labels)
+			obj.Labels[generatedByKindLabel] = kind
+			obj.Labels[generatedByNameLabel] = generator.GetName()
+
+			if len(repo.Annotations) > 0 {
+				if obj.Annotations == nil {
+					obj.Annotations = map[string]string{}
+				}
+				maps.Copy(obj.Annotations, repo.Annotations)
+			}
+
+			obj.Spec = repo.Spec
+			return controllerutil.SetControllerReference(generator, obj, scheme)
+		})
+		if errors.Is(err, errNotControlled) {
+			skipped = append(skipped, repo.Name)
+			continue
+		}
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("could not create or update repository %q: %w", repo.Name, err))
+			continue
+		}
+		if result != controllerutil.OperationResultNone {
+			changed++
+		}
+	}
+
+	if !prune {
+		return changed, skipped, errs
+	}
+
+	existing := &gollumv1alpha1.RepositoryList{}
+	if err := c.List(ctx, existing, client.InNamespace(generator.GetNamespace()), client.MatchingLabels{
+		generatedByKindLabel: kind,
+		generatedByNameLabel: generator.GetName(),
+	}); err != nil {
+		return changed, skipped, multierror.Append(errs, fmt.Errorf("could not list generated repositories: %w", err))
+	}
+
+	for idx := range existing.Items {
+		repo := &existing.Items[idx]
+		if desiredNames[repo.Name] || !isControlledBy(repo, generator) {
+			continue
+		}
+		if err := c.Delete(ctx, repo); client.IgnoreNotFound(err) != nil {
+			errs = multierror.Append(errs, fmt.Errorf("could not delete repository %q: %w", repo.Name, err))
+			continue
+		}
+		changed++
+	}
+
+	return changed, skipped, errs
+}
+
+// setSkippedCondition reports the Repositories that have not been generated as objects of the same name exist that are
+// not controlled by the generator. The condition is removed if no Repository has been skipped.
+func setSkippedCondition(conditions *[]metav1.Condition, generation int64, skipped []string) {
+	if len(skipped) == 0 {
+		meta.RemoveStatusCondition(conditions, "RepositoriesSkipped")
+		return
+	}
+
+	slices.Sort(skipped)
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               "RepositoriesSkipped",
+		Status:             metav1.ConditionTrue,
+		Reason:             "NotControlled",
+		Message:            fmt.Sprintf("Repositories exist that are not controlled by the generator: %s", strings.Join(skipped, ", ")),
+		ObservedGeneration: generation,
+	})
+}
+
+func isControlledBy(obj client.Object, owner client.Object) bool {
+	controller := metav1.GetControllerOf(obj)
+	return controller != nil && controller.UID == owner.GetUID()
+}
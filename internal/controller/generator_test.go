@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+
+	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetGeneratedRepositoryName(t *testing.T) {
+	tests := []struct {
+		owner, repo string
+		want        string
+	}{
+		{owner: "soerenschneider", repo: "gollum", want: "soerenschneider-gollum"},
+		{owner: "SoerenSchneider", repo: "Gollum", want: "soerenschneider-gollum"},
+		{owner: "a-b", repo: "c", want: "a-b-c-4e84717d"},
+		{owner: "a", repo: "b-c", want: "a-b-c-b88f83c8"},
+		{owner: "owner", repo: "foo.js", want: "owner-foo-js-c68feb0a"},
+		{owner: "owner", repo: "_private", want: "owner-private-29e45f75"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.owner+"/"+tt.repo, func(t *testing.T) {
+			got := getGeneratedRepositoryName(tt.owner, tt.repo)
+			if got != tt.want {
+				t.Errorf("getGeneratedRepositoryName() = %q, want %q", got, tt.want)
+			}
+			if errs := validation.IsDNS1123Subdomain(got); len(errs) > 0 {
+				t.Errorf("getGeneratedRepositoryName() = %q is not a valid name: %v", got, errs)
+			}
+		})
+	}
+}
+
+func TestRenderRepository(t *testing.T) {
+	generator := &gollumv1alpha1.RepositoryDiscovery{ObjectMeta: metav1.ObjectMeta{Namespace: "builds", Name: "discovery"}}
+	template := gollumv1alpha1.RepositoryTemplate{
+		Metadata: gollumv1alpha1.TemplateMetadata{
+			Labels:      map[string]string{"team": "platform"},
+			Annotations: map[string]string{"note": "generated"},
+		},
+	}
+	template.Spec.PipelineNames = map[gollumv1alpha1.ArtifactType]string{gollumv1alpha1.ArtifactsKeyReleaseAssets: "build"}
+
+	got := renderRepository(generator, template, "soerenschneider", "gollum")
+
+	want := gollumv1alpha1.Repository{}
+	want.Name = "soerenschneider-gollum"
+	want.Namespace = "builds"
+	want.Labels = map[string]string{"team": "platform"}
+	want.Annotations = map[string]string{"note": "generated"}
+	want.Spec.Owner = "soerenschneider"
+	want.Spec.Repository = "gollum"
+	want.Spec.PipelineNames = map[gollumv1alpha1.ArtifactType]string{gollumv1alpha1.ArtifactsKeyReleaseAssets: "build"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("renderRepository() = %+v, want %+v", got, want)
+	}
+
+	// the template is not shared with the rendered Repository
+	got.Labels["team"] = "other"
+	got.Spec.PipelineNames[gollumv1alpha1.ArtifactsKeyReleaseAssets] = "other"
+	if template.Metadata.Labels["team"] != "platform" || template.Spec.PipelineNames[gollumv1alpha1.ArtifactsKeyReleaseAssets] != "build" {
+		t.Errorf("expected template to be unchanged, got %+v", template)
+	}
+}
+
+func TestReconcileGeneratedRepositories(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := gollumv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	generator := &gollumv1alpha1.RepositorySet{ObjectMeta: metav1.ObjectMeta{Namespace: "builds", Name: "set", UID: "set-uid"}}
+	other := &gollumv1alpha1.RepositorySet{ObjectMeta: metav1.ObjectMeta{Namespace: "builds", Name: "other", UID: "other-uid"}}
+
+	newRepository := func(name string, controller client.Object) *gollumv1alpha1.Repository {
+		repo := &gollumv1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "builds",
+			Name:      name,
+			Labels:    map[string]string{generatedByKindLabel: repositorySetKind, generatedByNameLabel: "set"},
+		}}
+		if controller != nil {
+			repo.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(controller, gollumv1alpha1.GroupVersion.WithKind(repositorySetKind))}
+		}
+		return repo
+	}
+
+	tests := []struct {
+		name        string
+		existing    []client.Object
+		prune       bool
+		wantChanged int
+		wantSkipped []string
+		wantNames   []string
+	}{
+		{
+			name:        "create",
+			wantChanged: 2,
+			wantNames:   []string{"owner-a", "owner-b"},
+		},
+		{
+			name:        "prune repositories that are not desired anymore",
+			existing:    []client.Object{newRepository("owner-a", generator), newRepository("owner-c", generator)},
+			prune:       true,
+			wantChanged: 3,
+			wantNames:   []string{"owner-a", "owner-b"},
+		},
+		{
+			name:        "keep repositories that are not desired anymore",
+			existing:    []client.Object{newRepository("owner-c", generator)},
+			wantChanged: 2,
+			wantNames:   []string{"owner-a", "owner-b", "owner-c"},
+		},
+		{
+			name:        "do not prune repositories controlled by another generator",
+			existing:    []client.Object{newRepository("owner-c", other)},
+			prune:       true,
+			wantChanged: 2,
+			wantNames:   []string{"owner-a", "owner-b", "owner-c"},
+		},
+		{
+			name:        "do not adopt repositories written by hand",
+			existing:    []client.Object{newRepository("owner-a", nil)},
+			prune:       true,
+			wantChanged: 1,
+			wantSkipped: []string{"owner-a"},
+			wantNames:   []string{"owner-a", "owner-b"},
+		},
+		{
+			name:        "do not adopt repositories controlled by another generator",
+			existing:    []client.Object{newRepository("owner-b", other)},
+			wantChanged: 1,
+			wantSkipped: []string{"owner-b"},
+			wantNames:   []string{"owner-a", "owner-b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tt.existing...).Build()
+
+			desired := []gollumv1alpha1.Repository{
+				renderRepository(generator, gollumv1alpha1.RepositoryTemplate{}, "owner", "a"),
+				renderRepository(generator, gollumv1alpha1.RepositoryTemplate{}, "owner", "b"),
+			}
+			changed, skipped, err := reconcileGeneratedRepositories(context.Background(), c, scheme, generator, repositorySetKind, desired, tt.prune)
+			if err != nil {
+				t.Fatalf("reconcileGeneratedRepositories() error = %v", err)
+			}
+			if changed != tt.wantChanged || !slices.Equal(skipped, tt.wantSkipped) {
+				t.Errorf("reconcileGeneratedRepositories() = %d, %v, want %d, %v", changed, skipped, tt.wantChanged, tt.wantSkipped)
+			}
+
+			repos := &gollumv1alpha1.RepositoryList{}
+			if err := c.List(context.Background(), repos); err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, repo := range repos.Items {
+				names = append(names, repo.Name)
+				if slices.Contains(tt.wantSkipped, repo.Name) && isControlledBy(&repo, generator) {
+					t.Errorf("expected repository %q not to be adopted", repo.Name)
+				}
+			}
+			slices.Sort(names)
+			if !slices.Equal(names, tt.wantNames) {
+				t.Errorf("expected repositories %v, got %v", tt.wantNames, names)
+			}
+		})
+	}
+}
+
+func TestSetSkippedCondition(t *testing.T) {
+	var conditions []metav1.Condition
+
+	setSkippedCondition(&conditions, 1, []string{"owner-b", "owner-a"})
+	condition := meta.FindStatusCondition(conditions, "RepositoriesSkipped")
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Fatalf("expected RepositoriesSkipped condition, got %v", conditions)
+	}
+	if want := "Repositories exist that are not controlled by the generator: owner-a, owner-b"; condition.Message != want {
+		t.Errorf("message = %q, want %q", condition.Message, want)
+	}
+
+	setSkippedCondition(&conditions, 1, nil)
+	if len(conditions) != 0 {
+		t.Errorf("expected condition to be removed, got %v", conditions)
+	}
+}
@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultCredentialsSecretKey = "token"
+	credentialsSecretRefField   = ".spec.credentialsSecretRef.name"
+)
+
+var (
+	ErrProviderNotConfigured = errors.New("provider not configured")
+	ErrInvalidCredentials    = errors.New("invalid credentials secret")
+)
+
+// GithubClientFactory builds a GitHub client that authenticates using the given token.
+type GithubClientFactory func(token string) (GithubClient, error)
+
+// GiteaClientFactory builds a client for the Gitea/Forgejo instance reachable at the given base URL. If token is empty,
+// the globally configured token is used.
+type GiteaClientFactory func(baseUrl string, token string) (GithubClient, error)
+
+type cachedClient struct {
+	client  GithubClient
+	version string
+}
+
+// providerClients caches the clients that are built for Repositories that do not use the global GitHub client. Each
+// credential is using its own client, so rate-limit and authorization state is kept per credential. Clients are
+// evicted once no Repository uses them anymore.
+type providerClients struct {
+	mutex   sync.Mutex
+	clients map[string]cachedClient
+	// keys maps each Repository to the key of the client it uses
+	keys map[types.NamespacedName]string
+}
+
+// getOrCreate returns the cached client for the given key. If no client is cached or the cached client has been built
+// for a different version of the credentials, a new client is created.
+func (p *providerClients) getOrCreate(repo types.NamespacedName, key, version string, create func() (GithubClient, error)) (GithubClient, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if previous, found := p.keys[repo]; found && previous != key {
+		delete(p.keys, repo)
+		p.evictUnused(previous)
+	}
+
+	if cached, found := p.clients[key]; found && cached.version == version {
+		p.keys[repo] = key
+		return cached.client, nil
+	}
+
+	client, err := create()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.clients == nil {
+		p.clients = map[string]cachedClient{}
+		p.keys = map[types.NamespacedName]string{}
+	}
+	p.clients[key] = cachedClient{
+		client:  client,
+		version: version,
+	}
+	p.keys[repo] = key
+	return client, nil
+}
+
+// release marks the client used by the Repository as unused by it, e.g. because the Repository has been deleted or
+// uses the global client now.
+func (p *providerClients) release(repo types.NamespacedName) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if key, found := p.keys[repo]; found {
+		delete(p.keys, repo)
+		p.evictUnused(key)
+	}
+}
+
+// evictUnused removes the client for the key, unless another Repository still uses it. The mutex must be held.
+func (p *providerClients) evictUnused(key string) {
+	for _, used := range p.keys {
+		if used == key {
+			return
+		}
+	}
+	delete(p.clients, key)
+}
+
+// getGitClient returns the client that is able to talk to the provider the repository is hosted on, using the
+// credentials the repository references.
+func (r *RepositoryReconciler) getGitClient(ctx context.Context, data *gollumv1alpha1.Repository) (GithubClient, error) {
+	token, credentialsKey, err := r.getCredentials(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	tokenHash := hashToken(token)
+	repo := types.NamespacedName{Namespace: data.Namespace, Name: data.Name}
+
+	provider := data.Spec.Provider
+	if isGithubRepository(data) {
+		if credentialsKey == "" {
+			r.clients.release(repo)
+			if r.GithubClient == nil {
+				return nil, fmt.Errorf("%w: %s", ErrProviderNotConfigured, gollumv1alpha1.ProviderGithub)
+			}
+			return r.GithubClient, nil
+		}
+
+		if r.GithubClientFactory == nil {
+			return nil, fmt.Errorf("%w: %s", ErrProviderNotConfigured, gollumv1alpha1.ProviderGithub)
+		}
+
+		return r.clients.getOrCreate(repo, fmt.Sprintf("%s/%s", gollumv1alpha1.ProviderGithub, credentialsKey), tokenHash, func() (GithubClient, error) {
+			return r.GithubClientFactory(token)
+		})
+	}
+
+	if provider.Type != gollumv1alpha1.ProviderGitea {
+		return nil, fmt.Errorf("unknown provider %q", provider.Type)
+	}
+
+	if r.GiteaClientFactory == nil {
+		return nil, fmt.Errorf("%w: %s", ErrProviderNotConfigured, gollumv1alpha1.ProviderGitea)
+	}
+
+	baseUrl := strings.TrimSuffix(strings.TrimSpace(provider.URL), "/")
+	if baseUrl == "" {
+		return nil, errors.New("provider gitea requires an url")
+	}
+
+	return r.clients.getOrCreate(repo, fmt.Sprintf("%s/%s/%s", gollumv1alpha1.ProviderGitea, baseUrl, credentialsKey), tokenHash, func() (GithubClient, error) {
+		return r.GiteaClientFactory(baseUrl, token)
+	})
+}
+
+// getCredentials reads the token from the Secret referenced by the repository. It returns the token and the
+// namespaced name of the Secret, or empty values if the repository does not reference a Secret.
+func (r *RepositoryReconciler) getCredentials(ctx context.Context, data *gollumv1alpha1.Repository) (string, string, error) {
+	ref := data.Spec.CredentialsSecretRef
+	if ref == nil || ref.Name == "" {
+		return "", "", nil
+	}
+
+	secretName := types.NamespacedName{Namespace: data.Namespace, Name: ref.Name}
+	secret := &v1.Secret{}
+	if err := r.secretReader().Get(ctx, secretName, secret); err != nil {
+		return "", "", fmt.Errorf("%w %s: %w", ErrInvalidCredentials, secretName, err)
+	}
+
+	key := getCredentialsSecretKey(ref)
+	token := strings.TrimSpace(string(secret.Data[key]))
+	if token == "" {
+		return "", "", fmt.Errorf("%w %s: key %q is missing or empty", ErrInvalidCredentials, secretName, key)
+	}
+
+	return token, secretName.String(), nil
+}
+
+// secretReader returns the reader Secrets are read with. Secrets are only watched using their metadata, so they are
+// read from the API server rather than from the cache.
+func (r *RepositoryReconciler) secretReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+func getCredentialsSecretKey(ref *gollumv1alpha1.SecretKeyReference) string {
+	if ref.Key == "" {
+		return defaultCredentialsSecretKey
+	}
+	return ref.Key
+}
+
+func hashToken(token string) string {
+	if token == "" {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+	"github.com/soerenschneider/gollum/internal/github"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetCredentials(t *testing.T) {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "builds", Name: "github"},
+		Data: map[string][]byte{
+			"token":  []byte(" ghp_default\n"),
+			"custom": []byte("ghp_custom"),
+			"empty":  []byte(" "),
+		},
+	}
+	reconciler := &RepositoryReconciler{
+		APIReader: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build(),
+	}
+
+	tests := []struct {
+		name      string
+		ref       *gollumv1alpha1.SecretKeyReference
+		wantToken string
+		wantKey   string
+		wantErr   bool
+	}{
+		{
+			name: "no reference",
+		},
+		{
+			name:      "default key",
+			ref:       &gollumv1alpha1.SecretKeyReference{Name: "github"},
+			wantToken: "ghp_default",
+			wantKey:   "builds/github",
+		},
+		{
+			name:      "custom key",
+			ref:       &gollumv1alpha1.SecretKeyReference{Name: "github", Key: "custom"},
+			wantToken: "ghp_custom",
+			wantKey:   "builds/github",
+		},
+		{
+			name:    "empty key",
+			ref:     &gollumv1alpha1.SecretKeyReference{Name: "github", Key: "empty"},
+			wantErr: true,
+		},
+		{
+			name:    "missing secret",
+			ref:     &gollumv1alpha1.SecretKeyReference{Name: "gitea"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &gollumv1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Namespace: "builds", Name: "repo"}}
+			repo.Spec.CredentialsSecretRef = tt.ref
+
+			token, key, err := reconciler.getCredentials(context.Background(), repo)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getCredentials() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrInvalidCredentials) {
+				t.Errorf("getCredentials() error = %v, want ErrInvalidCredentials", err)
+			}
+			if token != tt.wantToken || key != tt.wantKey {
+				t.Errorf("getCredentials() got = %q, %q, want %q, %q", token, key, tt.wantToken, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestProviderClients(t *testing.T) {
+	repoA := types.NamespacedName{Namespace: "builds", Name: "a"}
+	repoB := types.NamespacedName{Namespace: "builds", Name: "b"}
+
+	created := 0
+	create := func() (GithubClient, error) {
+		created++
+		return github.NewGithubClient(nil)
+	}
+
+	clients := &providerClients{}
+	first, _ := clients.getOrCreate(repoA, "github/builds/token", "v1", create)
+	second, _ := clients.getOrCreate(repoB, "github/builds/token", "v1", create)
+	if created != 1 || first != second {
+		t.Fatalf("expected client to be shared, created %d clients", created)
+	}
+
+	// rotated credentials
+	_, _ = clients.getOrCreate(repoA, "github/builds/token", "v2", create)
+	if created != 2 {
+		t.Fatalf("expected client to be rebuilt for new credentials, created %d clients", created)
+	}
+
+	// repoA switches to another secret, the client is still used by repoB
+	_, _ = clients.getOrCreate(repoA, "github/builds/other", "v1", create)
+	if _, found := clients.clients["github/builds/token"]; !found {
+		t.Fatal("expected client used by another repository to be kept")
+	}
+
+	clients.release(repoB)
+	if _, found := clients.clients["github/builds/token"]; found {
+		t.Fatal("expected unused client to be evicted")
+	}
+
+	clients.release(repoA)
+	if len(clients.clients) != 0 || len(clients.keys) != 0 {
+		t.Fatalf("expected all clients to be evicted, got %v", clients.clients)
+	}
+}
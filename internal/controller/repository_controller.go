@@ -5,6 +5,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -20,13 +22,18 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/strings/slices"
-	"knative.dev/pkg/apis"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
 )
@@ -35,6 +42,9 @@ type PipelineRunner interface {
 	GetPipeline(ctx context.Context, namespace, name string) (*pipelinev1.Pipeline, error)
 	GetPipelineRun(ctx context.Context, namespace, name string) (*pipelinev1.PipelineRun, error)
 	CreatePipelineRun(ctx context.Context, req tekton.CreatePipelineRunRequest) (*pipelinev1.PipelineRun, error)
+	GetTask(ctx context.Context, namespace, name string) (*pipelinev1.Task, error)
+	GetTaskRun(ctx context.Context, namespace, name string) (*pipelinev1.TaskRun, error)
+	CreateTaskRun(ctx context.Context, req tekton.CreatePipelineRunRequest) (*pipelinev1.TaskRun, error)
 }
 
 type GithubClient interface {
@@ -43,10 +53,42 @@ type GithubClient interface {
 	GetPackages(ctx context.Context, query github.ArtifactQuery) ([]github.Package, error)
 }
 
+// ChangeDetector is implemented by clients that are able to cheaply detect whether a repository may have new releases.
+type ChangeDetector interface {
+	HasReleaseEvents(ctx context.Context, owner, repo string) (bool, error)
+	// ConfirmReleaseEvents marks the detected events as processed, so they are not reported again.
+	ConfirmReleaseEvents(owner, repo string)
+	// Forget drops the state kept for detecting changes of the repository.
+	Forget(owner, repo string)
+}
+
+// TagResolver is implemented by clients that are able to resolve the commit a tag points to.
+type TagResolver interface {
+	ResolveTag(ctx context.Context, owner, repo, tag string) (string, error)
+}
+
 type Requeue interface {
 	Requeue(duration time.Duration) time.Duration
 }
 
+// Scheduler spreads polling of all repositories so the request budget is not exceeded.
+type Scheduler interface {
+	Observe(key string, cost int)
+	Forget(key string)
+	Interval() time.Duration
+	NextPoll(key string) time.Duration
+}
+
+// CadenceRequeue learns the poll interval of a repository from the timestamps of its releases.
+type CadenceRequeue interface {
+	Interval(releases []time.Time) (time.Duration, bool)
+}
+
+// BuildWindow decides when PipelineRuns may be created.
+type BuildWindow interface {
+	NextActive(t time.Time) (time.Time, error)
+}
+
 type VersionFilter interface {
 	Matches(version string) (bool, error)
 }
@@ -59,19 +101,52 @@ type ReleaseArtifactChecker interface {
 type RepositoryReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// APIReader reads objects that are not cached, such as credential Secrets. The client is used if it is nil.
+	APIReader client.Reader
 
 	Recorder       record.EventRecorder
 	PipelineRunner PipelineRunner
 	GithubClient   GithubClient
 	Requeue        Requeue
 
+	// GithubClientFactory is used to build clients for Repositories that reference their own credentials.
+	GithubClientFactory GithubClientFactory
+	// GiteaClientFactory is used to build clients for Repositories hosted on Gitea/Forgejo instances. Support for
+	// those providers is disabled if it is nil.
+	GiteaClientFactory GiteaClientFactory
+	clients            providerClients
+
+	// Scheduler computes the poll interval from the hourly request budget. If it is nil, repositories are polled
+	// using the DefaultRequeueInterval.
+	Scheduler Scheduler
+	// Cadence adapts the poll interval to the release cadence of each repository. If it is nil, all repositories are
+	// polled in the same interval.
+	Cadence CadenceRequeue
+	// BuildWindow restricts the creation of PipelineRuns for all repositories that do not configure their own build
+	// window. PipelineRuns may be created at any time if it is nil.
+	BuildWindow BuildWindow
+	// DetectChanges enables polling the events of a repository, so releases and artifacts are only fetched once a
+	// release related event shows up.
+	DetectChanges bool
+	// triggers receives Repositories that need to be reconciled immediately.
+	triggers chan event.GenericEvent
+	// triggered holds the Repositories that have been triggered and need a full check regardless of detected changes.
+	triggered triggeredRepositories
+	// watched holds the repositories changes are detected for.
+	watched watchedRepositories
+
 	DefaultRequeueInterval time.Duration
 	DefaultJitterPercent   float64
 }
 
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups="tekton.dev",resources=pipelines,verbs=get;list;watch
 // +kubebuilder:rbac:groups="tekton.dev",resources=pipelineruns,verbs=create;patch;get;list;watch
+// +kubebuilder:rbac:groups="tekton.dev",resources=tasks,verbs=get;list;watch
+// +kubebuilder:rbac:groups="tekton.dev",resources=taskruns,verbs=create;patch;get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=gollum.soeren.cloud,resources=buildprofiles,verbs=get;list;watch
 // +kubebuilder:rbac:groups=gollum.soeren.cloud,resources=repositories,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=gollum.soeren.cloud,resources=repositories/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=gollum.soeren.cloud,resources=repositories/finalizers,verbs=update
@@ -79,6 +154,12 @@ func (r *RepositoryReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	data := &gollumv1alpha1.Repository{}
 	if err := r.Get(ctx, req.NamespacedName, data); err != nil {
 		if apierrors.IsNotFound(err) {
+			if r.Scheduler != nil {
+				r.Scheduler.Forget(req.NamespacedName.String())
+			}
+			r.clients.release(req.NamespacedName)
+			r.triggered.consume(req.NamespacedName)
+			r.forgetReleaseEvents(ctx, req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, err
@@ -93,23 +174,84 @@ func (r *RepositoryReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 
 	initStatus(data)
 
-	if err := r.checkIfPipelineExists(ctx, data, req.Namespace); err != nil {
-		requeueAfter := requeue.JitterPercentageAdditive(r.Requeue.Requeue(r.DefaultRequeueInterval), r.DefaultJitterPercent)
+	if err := r.applyBuildProfile(ctx, data); err != nil {
+		meta.SetStatusCondition(data.GetConditions(), metav1.Condition{
+			Type:    "BuildProfileUnavailable",
+			Status:  metav1.ConditionTrue,
+			Reason:  "BuildProfileNotFound",
+			Message: err.Error(),
+		})
+		// creating the BuildProfile triggers a reconcile
+		requeueAfter := r.getPollRequeueAfter(data)
+		logger.Error(err, "could not apply build profile", "owner", data.Spec.Owner, "repo", data.Spec.Repository, "requeue_after", requeueAfter)
+		metrics.RequeueAfter.WithLabelValues(data.Spec.Owner, data.Spec.Repository).Set(requeueAfter.Seconds())
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+	meta.RemoveStatusCondition(data.GetConditions(), "BuildProfileUnavailable")
+
+	pipelines, err := r.checkIfPipelineExists(ctx, data, req.Namespace)
+	if err != nil {
+		requeueAfter := r.getPollRequeueAfter(data)
 		metrics.RequeueAfter.WithLabelValues(data.Spec.Owner, data.Spec.Repository).Set(requeueAfter.Seconds())
 		logger.Error(err, "could not find desired pipeline, make sure to install it first", "owner", data.Spec.Owner, "repo", data.Spec.Repository, "requeue_after", requeueAfter)
 		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
 
+	if err := r.checkPipelineCompatibility(data, pipelines, req.Namespace); err != nil {
+		requeueAfter := r.getPollRequeueAfter(data)
+		metrics.RequeueAfter.WithLabelValues(data.Spec.Owner, data.Spec.Repository).Set(requeueAfter.Seconds())
+		logger.Error(err, "pipeline is incompatible with the params and workspaces of the repository", "owner", data.Spec.Owner, "repo", data.Spec.Repository, "requeue_after", requeueAfter)
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
 	r.cleanupRuns(ctx, req.Namespace, data)
 
+	gitClient, err := r.getGitClient(ctx, data)
+	if err != nil {
+		reason := "InvalidProvider"
+		if errors.Is(err, ErrInvalidCredentials) {
+			reason = "InvalidCredentials"
+		}
+		meta.SetStatusCondition(data.GetConditions(), metav1.Condition{
+			Type:    "ProviderUnavailable",
+			Status:  metav1.ConditionTrue,
+			Reason:  reason,
+			Message: err.Error(),
+		})
+		requeueAfter := r.getPollRequeueAfter(data)
+		logger.Error(err, "could not get client for provider", "owner", data.Spec.Owner, "repo", data.Spec.Repository, "requeue_after", requeueAfter)
+		metrics.RequeueAfter.WithLabelValues(data.Spec.Owner, data.Spec.Repository).Set(requeueAfter.Seconds())
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+	meta.RemoveStatusCondition(data.GetConditions(), "ProviderUnavailable")
+
+	if r.DetectChanges && !r.triggered.consume(req.NamespacedName) && !r.hasChanges(ctx, gitClient, data) {
+		meta.SetStatusCondition(data.GetConditions(), metav1.Condition{
+			Type:    "NoRunsNeeded",
+			Status:  metav1.ConditionTrue,
+			Message: "No release related events since the last check",
+			Reason:  "NoChangesDetected",
+		})
+
+		requeueAfter := r.getPollRequeueAfter(data)
+		logger.Info("no changes detected", "owner", data.Spec.Owner, "repo", data.Spec.Repository, "requeue_after", requeueAfter)
+		metrics.RequeueAfter.WithLabelValues(data.Spec.Owner, data.Spec.Repository).Set(requeueAfter.Seconds())
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
 	metrics.LastReleaseCheck.WithLabelValues(data.Spec.Owner, data.Spec.Repository).SetToCurrentTime()
-	releases, rateLimitReset, err := r.getReleasesForRepository(ctx, data)
+	releases, rateLimitReset, err := r.getReleasesForRepository(ctx, gitClient, data)
 	if err != nil {
-		requeueAfter := cmp.Or(rateLimitReset, requeue.JitterPercentageDistributed(r.Requeue.Requeue(r.DefaultRequeueInterval), r.DefaultJitterPercent))
+		requeueAfter := cmp.Or(rateLimitReset, r.getPollRequeueAfter(data))
 		logger.Error(err, "could not get releases from Github", "requeue_after", requeueAfter)
 		metrics.RequeueAfter.WithLabelValues(data.Spec.Owner, data.Spec.Repository).Set(requeueAfter.Seconds())
 		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
+	data.Status.LastFullCheck = &metav1.Time{Time: time.Now()}
+	data.Status.ObservedGeneration = data.Generation
+	if detector, ok := gitClient.(ChangeDetector); ok {
+		detector.ConfirmReleaseEvents(data.Spec.Owner, data.Spec.Repository)
+	}
 
 	filteredReleases, err := r.applyVersionFilter(ctx, data, releases)
 	if err != nil {
@@ -117,9 +259,13 @@ func (r *RepositoryReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 	logger.Info("Found unseen release(s)", "unseen", len(releases), "filtered", len(filteredReleases), "owner", data.Spec.Owner, "repo", data.Spec.Repository)
 
-	releaseArtifacts, rateLimitReset := r.fetchArtifactDataForReleases(ctx, data, filteredReleases)
+	releaseArtifacts, rateLimitReset := r.fetchArtifactDataForReleases(ctx, gitClient, data, filteredReleases)
+	if r.Scheduler != nil && gitClient == r.GithubClient {
+		r.Scheduler.Observe(client.ObjectKeyFromObject(data).String(), estimatePollCost(data, releases, filteredReleases))
+	}
 	releasesWithMissingArtifacts := r.checkReleaseDataForMissingArtifacts(data, releaseArtifacts)
 	if len(releasesWithMissingArtifacts) == 0 {
+		meta.RemoveStatusCondition(data.GetConditions(), "PendingBuildWindow")
 		meta.SetStatusCondition(data.GetConditions(), metav1.Condition{
 			Type:    "NoRunsNeeded",
 			Status:  metav1.ConditionTrue,
@@ -127,13 +273,29 @@ func (r *RepositoryReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			Reason:  "NoMissingReleases",
 		})
 
-		requeueAfter := cmp.Or(rateLimitReset, requeue.JitterPercentageDistributed(r.Requeue.Requeue(r.DefaultRequeueInterval), r.DefaultJitterPercent))
+		requeueAfter := cmp.Or(rateLimitReset, r.getPollRequeueAfter(data))
 		logger.Info("no releases with missing artifacts available", "owner", data.Spec.Owner, "repo", data.Spec.Repository, "requeue_after", requeueAfter)
 		metrics.RequeueAfter.WithLabelValues(data.Spec.Owner, data.Spec.Repository).Set(requeueAfter.Seconds())
 		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
 
-	backoffDuration, err := r.createPipelineRunsForReleases(ctx, data, releasesWithMissingArtifacts, req.Namespace)
+	if opensAt, isPending := r.isPendingBuildWindow(data); isPending {
+		meta.SetStatusCondition(data.GetConditions(), metav1.Condition{
+			Type:    "PendingBuildWindow",
+			Status:  metav1.ConditionTrue,
+			Reason:  "OutsideBuildWindow",
+			Message: fmt.Sprintf("Creating PipelineRuns for %d release(s) is deferred until %s", len(releasesWithMissingArtifacts), opensAt.Format(time.RFC3339)),
+		})
+
+		// keep polling in the meantime, but wake up as soon as the build window opens
+		requeueAfter := min(time.Until(opensAt), cmp.Or(rateLimitReset, r.getPollRequeueAfter(data)))
+		logger.Info("releases with missing artifacts are pending the build window", "owner", data.Spec.Owner, "repo", data.Spec.Repository, "opens_at", opensAt, "requeue_after", requeueAfter)
+		metrics.RequeueAfter.WithLabelValues(data.Spec.Owner, data.Spec.Repository).Set(requeueAfter.Seconds())
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+	meta.RemoveStatusCondition(data.GetConditions(), "PendingBuildWindow")
+
+	backoffDuration, err := r.createPipelineRunsForReleases(ctx, gitClient, pipelines, data, releasesWithMissingArtifacts, req.Namespace)
 	if err != nil {
 		requeueAfter := maxOrDefault(rateLimitReset, backoffDuration)
 		logger.Error(err, "errors while creating pipelines for releases", "owner", data.Spec.Owner, "repo", data.Spec.Repository, "requeue_after", requeueAfter)
@@ -141,18 +303,181 @@ func (r *RepositoryReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
 
-	requeueAfter := cmp.Or(rateLimitReset, requeue.JitterPercentageDistributed(r.Requeue.Requeue(r.DefaultRequeueInterval), r.DefaultJitterPercent))
+	requeueAfter := cmp.Or(rateLimitReset, r.getPollRequeueAfter(data))
 	logger.Info("finished processing repository", "owner", data.Spec.Owner, "repo", data.Spec.Repository, "requeue_after", requeueAfter)
+	metrics.RequeueAfter.WithLabelValues(data.Spec.Owner, data.Spec.Repository).Set(requeueAfter.Seconds())
 	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
-func (r *RepositoryReconciler) checkIfPipelineExists(ctx context.Context, data *gollumv1alpha1.Repository, namespace string) error {
+// getPollRequeueAfter returns the duration after which the repository is polled again. The schedule of the repository
+// takes precedence, otherwise the interval is learned from the release cadence if possible. If a scheduler is
+// configured, the repository is polled in its slot of the quota-aware interval, otherwise the interval plus jitter is
+// used.
+func (r *RepositoryReconciler) getPollRequeueAfter(data *gollumv1alpha1.Repository) time.Duration {
+	requeuer := r.Requeue
+	jitterPercent := r.DefaultJitterPercent
+
+	// the learned interval is only shown if it is used
+	data.Status.PollInterval = nil
+	meta.RemoveStatusCondition(data.GetConditions(), "InvalidSchedule")
+	if schedule := data.Spec.Schedule; schedule != nil {
+		if schedule.Cron != "" {
+			requeueAfter, err := getCronRequeueAfter(schedule.Cron, time.Now())
+			if err == nil {
+				return requeueAfter
+			}
+			setInvalidScheduleCondition(data, fmt.Errorf("invalid cron expression: %w", err))
+		}
+
+		if schedule.ActiveHours != nil {
+			onHoursRequeue, err := requeue.NewOnHoursRequeue(schedule.ActiveHours.From, schedule.ActiveHours.To)
+			if err != nil {
+				setInvalidScheduleCondition(data, fmt.Errorf("invalid active hours: %w", err))
+			} else {
+				requeuer = onHoursRequeue
+			}
+		}
+
+		if schedule.JitterPercent != nil {
+			jitterPercent = float64(*schedule.JitterPercent)
+		}
+
+		if schedule.Interval != nil && schedule.Interval.Duration > 0 {
+			return requeue.JitterPercentageDistributed(requeuer.Requeue(max(schedule.Interval.Duration, minScheduleInterval)), jitterPercent)
+		}
+	}
+
+	var learnedInterval time.Duration
+	if r.Cadence != nil {
+		if interval, ok := r.Cadence.Interval(getReleaseTimestamps(data)); ok {
+			learnedInterval = interval
+			data.Status.PollInterval = &metav1.Duration{Duration: interval}
+		}
+	}
+
+	if r.Scheduler == nil {
+		return requeue.JitterPercentageDistributed(requeuer.Requeue(cmp.Or(learnedInterval, r.DefaultRequeueInterval)), jitterPercent)
+	}
+
+	metrics.PollIntervalSeconds.Set(r.Scheduler.Interval().Seconds())
+	return requeuer.Requeue(max(r.Scheduler.NextPoll(client.ObjectKeyFromObject(data).String()), learnedInterval))
+}
+
+// setInvalidScheduleCondition reports a schedule that can not be used. It is a condition rather than an event, as the
+// schedule is evaluated on every reconcile.
+func setInvalidScheduleCondition(data *gollumv1alpha1.Repository, err error) {
+	meta.SetStatusCondition(data.GetConditions(), metav1.Condition{
+		Type:    "InvalidSchedule",
+		Status:  metav1.ConditionTrue,
+		Reason:  "InvalidSchedule",
+		Message: err.Error(),
+	})
+}
+
+// hasChanges returns whether releases and artifacts of the repository need to be fetched. A full check is needed if
+// the client is not able to detect changes, the spec changed, artifacts are still missing, the last full check is too
+// long ago or a release related event showed up.
+func (r *RepositoryReconciler) hasChanges(ctx context.Context, gitClient GithubClient, data *gollumv1alpha1.Repository) bool {
+	detector, ok := gitClient.(ChangeDetector)
+	if !ok {
+		return true
+	}
+
+	r.watched.set(client.ObjectKeyFromObject(data), data.Spec.Owner, data.Spec.Repository)
+	if needsFullCheck(data, time.Now()) {
+		return true
+	}
+
+	changed, err := detector.HasReleaseEvents(ctx, data.Spec.Owner, data.Spec.Repository)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "could not detect changes, falling back to full check", "owner", data.Spec.Owner, "repo", data.Spec.Repository)
+		return true
+	}
+	return changed
+}
+
+// forgetReleaseEvents drops the events state of the repository the deleted Repository watched using the global
+// client, unless another Repository still watches it. Clients built for the credentials of a Repository are evicted
+// with their state once they are unused.
+func (r *RepositoryReconciler) forgetReleaseEvents(ctx context.Context, repo types.NamespacedName) {
+	owner, name, found := r.watched.remove(repo)
+	if !found {
+		return
+	}
+
+	detector, ok := r.GithubClient.(ChangeDetector)
+	if !ok {
+		return
+	}
+
+	repos := &gollumv1alpha1.RepositoryList{}
+	if err := r.List(ctx, repos, client.MatchingFields{ownerRepoField: getOwnerRepoKey(owner, name)}); err != nil {
+		log.FromContext(ctx).Error(err, "could not list repositories", "owner", owner, "repo", name)
+		return
+	}
+	if len(repos.Items) == 0 {
+		detector.Forget(owner, name)
+	}
+}
+
+// isPendingBuildWindow returns whether PipelineRuns for the repository may not be created right now and the time the
+// build window opens. An invalid build window of the repository is reported in a condition and the global build window
+// is used instead.
+func (r *RepositoryReconciler) isPendingBuildWindow(data *gollumv1alpha1.Repository) (time.Time, bool) {
+	meta.RemoveStatusCondition(data.GetConditions(), "InvalidBuildWindow")
+
+	buildWindow := r.BuildWindow
+	if data.Spec.BuildWindow != nil {
+		repoBuildWindow, err := getBuildWindow(data.Spec.BuildWindow)
+		if err != nil {
+			setInvalidBuildWindowCondition(data, err)
+		} else {
+			buildWindow = repoBuildWindow
+		}
+	}
+
+	if buildWindow == nil {
+		return time.Time{}, false
+	}
+
+	now := time.Now()
+	opensAt, err := buildWindow.NextActive(now)
+	if err != nil {
+		setInvalidBuildWindowCondition(data, err)
+		return time.Time{}, false
+	}
+
+	return opensAt, opensAt.After(now)
+}
+
+// setInvalidBuildWindowCondition reports a build window that can not be used. Like an invalid schedule, it is a
+// condition rather than an event, as the build window is evaluated on every reconcile.
+func setInvalidBuildWindowCondition(data *gollumv1alpha1.Repository, err error) {
+	meta.SetStatusCondition(data.GetConditions(), metav1.Condition{
+		Type:    "InvalidBuildWindow",
+		Status:  metav1.ConditionTrue,
+		Reason:  "InvalidBuildWindow",
+		Message: err.Error(),
+	})
+}
+
+// checkIfPipelineExists fetches the Pipelines, Tasks or inline pipelineSpecs of all artifact types.
+func (r *RepositoryReconciler) checkIfPipelineExists(ctx context.Context, data *gollumv1alpha1.Repository, namespace string) (map[gollumv1alpha1.ArtifactType]*pipelinev1.PipelineSpec, error) {
+	// remotely resolved pipelines are not required to exist in the namespace, resolution errors are reported by
+	// Tekton in the status of the PipelineRun
+	if data.Spec.PipelineResolver != nil {
+		return nil, nil
+	}
+
 	var errs error
+	pipelines := make(map[gollumv1alpha1.ArtifactType]*pipelinev1.PipelineSpec, len(data.Spec.PipelineNames))
 
-	for _, pipelineName := range data.Spec.PipelineNames {
-		_, err := r.PipelineRunner.GetPipeline(ctx, namespace, pipelineName)
+	for artifactType, pipelineName := range data.Spec.PipelineNames {
+		pipeline, err := r.getBuildTargetSpec(ctx, data.Spec.BuildTarget, namespace, pipelineName)
 		if err != nil {
 			errs = multierror.Append(errs, err)
+		} else {
+			pipelines[artifactType] = pipeline
 		}
 	}
 
@@ -160,7 +485,11 @@ func (r *RepositoryReconciler) checkIfPipelineExists(ctx context.Context, data *
 		reason := "Unknown"
 		if errors.Is(errs, tekton.ErrTektonPipelineNotFound) {
 			reason = "PipelineNotFound"
-		} else if errors.Is(errs, tekton.ErrTektonGetPipelineForbidden) {
+		} else if errors.Is(errs, tekton.ErrTektonTaskNotFound) {
+			reason = "TaskNotFound"
+		} else if errors.Is(errs, ErrPipelineSpecNotFound) {
+			reason = "PipelineSpecNotFound"
+		} else if errors.Is(errs, tekton.ErrTektonGetPipelineForbidden) || errors.Is(errs, tekton.ErrTektonGetTaskForbidden) {
 			reason = "Forbidden"
 		}
 
@@ -168,11 +497,53 @@ func (r *RepositoryReconciler) checkIfPipelineExists(ctx context.Context, data *
 			Type:    "TektonPipelineUnavailable",
 			Status:  metav1.ConditionFalse,
 			Reason:  reason,
-			Message: "Requested Tekton Pipeline not available",
+			Message: errs.Error(),
 		})
 	}
 
-	return errs
+	return pipelines, errs
+}
+
+// checkPipelineCompatibility compares the params and workspaces that are sent to the pipelines with the ones they
+// declare and sets the PipelineIncompatible condition listing the mismatches.
+func (r *RepositoryReconciler) checkPipelineCompatibility(data *gollumv1alpha1.Repository, pipelines map[gollumv1alpha1.ArtifactType]*pipelinev1.PipelineSpec, namespace string) error {
+	// parsing the templates is sufficient to learn the names and types of the params
+	params, err := tekton.RenderParams(data.Spec.Params, nil)
+	if err != nil {
+		return err
+	}
+
+	var mismatches []string
+	for _, artifactType := range gollumv1alpha1.ArtifactTypes() {
+		pipeline, found := pipelines[artifactType]
+		if !found {
+			continue
+		}
+
+		pipelineRunRequest := tekton.BuildRunRequest("", namespace, data, artifactType)
+		if pipelineRunRequest == nil {
+			continue
+		}
+		pipelineRunRequest.RenderedParams = params
+
+		for _, mismatch := range tekton.CheckCompatibility(*pipelineRunRequest, *pipeline) {
+			mismatches = append(mismatches, fmt.Sprintf("pipeline %s: %s", pipelineRunRequest.PipelineName, mismatch))
+		}
+	}
+
+	if len(mismatches) == 0 {
+		meta.RemoveStatusCondition(data.GetConditions(), "PipelineIncompatible")
+		return nil
+	}
+
+	message := strings.Join(mismatches, "; ")
+	meta.SetStatusCondition(data.GetConditions(), metav1.Condition{
+		Type:    "PipelineIncompatible",
+		Status:  metav1.ConditionTrue,
+		Reason:  "DeclarationMismatch",
+		Message: message,
+	})
+	return errors.New(message)
 }
 
 // cleanupRuns cleans up outdated and PipelineRuns that have been deleted from the status field.
@@ -185,9 +556,9 @@ func (r *RepositoryReconciler) cleanupRuns(ctx context.Context, namespace string
 
 	for version, release := range data.Status.Releases {
 		for artifactType, runs := range release.MostRecentRuns {
-			pipelineRun, err := r.PipelineRunner.GetPipelineRun(ctx, namespace, runs.Name)
-			isExpired := pipelineRun != nil && isPipelineRunExpired(pipelineRun.CreationTimestamp.Time)
-			isNotFound := errors.Is(err, tekton.ErrTektonPipelineNotFound)
+			run, err := r.getRunState(ctx, namespace, runs)
+			isExpired := run != nil && isPipelineRunExpired(run.creationTimestamp)
+			isNotFound := isRunNotFound(err)
 
 			if isExpired || isNotFound {
 				_, hasVersion := pipelineRunsToRemove[version]
@@ -210,23 +581,23 @@ func (r *RepositoryReconciler) cleanupRuns(ctx context.Context, namespace string
 	}
 }
 
-func (r *RepositoryReconciler) getReleasesForRepository(ctx context.Context, data *gollumv1alpha1.Repository) ([]github.Release, time.Duration, error) {
+func (r *RepositoryReconciler) getReleasesForRepository(ctx context.Context, gitClient GithubClient, data *gollumv1alpha1.Repository) ([]github.Release, time.Duration, error) {
 	ghReleasesRequest := buildReleaseRequest(data)
-	releases, err := r.GithubClient.GetReleases(ctx, ghReleasesRequest)
+	releases, err := gitClient.GetReleases(ctx, ghReleasesRequest)
 	if err != nil {
 		log.FromContext(ctx).Error(err, "could not fetch release info from GitHub")
 
-		var rlErr *github.RateLimitError
-		if errors.As(err, &rlErr) {
+		if retryAt, isRateLimited := github.RetryAt(err); isRateLimited {
+			reason := getRateLimitReason(err)
 			meta.SetStatusCondition(data.GetConditions(), metav1.Condition{
 				Type:    "FetchReleaseInformationFailed",
 				Status:  metav1.ConditionFalse,
-				Reason:  "RateLimitExceeded",
+				Reason:  reason,
 				Message: "Fetching release information from GitHub failed",
 			})
 
-			r.Recorder.Event(data, v1.EventTypeWarning, "RateLimitExceeded", "Could not fetch missing releases")
-			requeueAfter := requeue.JitterFixAdditive(r.Requeue.Requeue(time.Until(rlErr.Info.Reset)), 10)
+			r.Recorder.Event(data, v1.EventTypeWarning, reason, "Could not fetch missing releases")
+			requeueAfter := requeue.JitterFixAdditive(r.Requeue.Requeue(time.Until(retryAt)), 10)
 			return nil, requeueAfter, err
 		}
 
@@ -255,6 +626,9 @@ func (r *RepositoryReconciler) checkReleaseDataForMissingArtifacts(data *gollumv
 				MissingArtifacts: make(map[gollumv1alpha1.ArtifactType]bool),
 			}
 		}
+		if data.Status.Releases[tagName].PublishedAt == nil && release.Release.PublishedAt != nil {
+			data.Status.Releases[tagName].PublishedAt = &metav1.Time{Time: *release.Release.PublishedAt}
+		}
 
 		for _, artifactType := range gollumv1alpha1.ArtifactTypes() {
 			_, hasPipelineDefined := data.Spec.PipelineNames[artifactType]
@@ -274,12 +648,12 @@ func (r *RepositoryReconciler) checkReleaseDataForMissingArtifacts(data *gollumv
 	return releasesWithMissingArtifacts
 }
 
-func (r *RepositoryReconciler) createRunsForRelease(ctx context.Context, namespace string, data *gollumv1alpha1.Repository, rel github.Release) (int, error) {
+func (r *RepositoryReconciler) createRunsForRelease(ctx context.Context, gitClient GithubClient, pipelines map[gollumv1alpha1.ArtifactType]*pipelinev1.PipelineSpec, namespace string, data *gollumv1alpha1.Repository, rel github.Release) (int, error) {
 	var errs error
 	startedRuns := 0
 
 	for _, artType := range gollumv1alpha1.ArtifactTypes() {
-		created, err := r.createRun(ctx, namespace, data, rel, artType)
+		created, err := r.createRun(ctx, gitClient, pipelines[artType], namespace, data, rel, artType)
 		if err != nil {
 			errs = multierror.Append(errs, err)
 		} else {
@@ -290,7 +664,7 @@ func (r *RepositoryReconciler) createRunsForRelease(ctx context.Context, namespa
 	return startedRuns, errs
 }
 
-func (r *RepositoryReconciler) createRun(ctx context.Context, namespace string, data *gollumv1alpha1.Repository, rel github.Release, artifactType gollumv1alpha1.ArtifactType) (int, error) {
+func (r *RepositoryReconciler) createRun(ctx context.Context, gitClient GithubClient, pipeline *pipelinev1.PipelineSpec, namespace string, data *gollumv1alpha1.Repository, rel github.Release, artifactType gollumv1alpha1.ArtifactType) (int, error) {
 	logger := log.FromContext(ctx)
 
 	pipelineRunRequest := tekton.BuildRunRequest(rel.TagName, namespace, data, artifactType)
@@ -302,31 +676,51 @@ func (r *RepositoryReconciler) createRun(ctx context.Context, namespace string,
 	createdRuns, found := data.Status.Releases[rel.TagName]
 	if found && createdRuns != nil && createdRuns.MostRecentRuns != nil && createdRuns.MostRecentRuns[artifactType] != nil {
 		pipelineRunName := createdRuns.MostRecentRuns[artifactType].Name
-		pipelineRun, err := r.PipelineRunner.GetPipelineRun(ctx, namespace, pipelineRunName)
+		pipelineRun, err := r.getRunState(ctx, namespace, createdRuns.MostRecentRuns[artifactType])
 		if err != nil {
 			logger.Info("Could not get PipelineRun", "pipelinerun", pipelineRunName, "error", err)
 		} else {
-			hasStarted := pipelineRun.Status.StartTime != nil
-			hasCompleted := hasStarted && pipelineRun.Status.CompletionTime != nil
-			hasStartedRecently := time.Since(pipelineRun.Status.StartTime.Time) < 60*time.Minute
-			isSucceeded := false
-			for _, condition := range pipelineRun.Status.Conditions {
-				if condition.Type == apis.ConditionSucceeded && condition.IsTrue() {
-					isSucceeded = true
-				}
-			}
+			hasStarted := pipelineRun.startTime != nil
+			hasCompleted := hasStarted && pipelineRun.completionTime != nil
+			hasStartedRecently := hasStarted && time.Since(pipelineRun.startTime.Time) < 60*time.Minute
 
 			if hasStarted && !hasCompleted && hasStartedRecently {
 				logger.Info("Found previous PipelineRun that is not completed, yet", "run", pipelineRunName)
 				return 0, nil
 			} else if hasCompleted {
-				logger.Info("Found previous PipelineRun that has completed some time ago but produced no artifacts, starting new PipelineRun", "run", pipelineRunName, "success", isSucceeded)
+				logger.Info("Found previous PipelineRun that has completed some time ago but produced no artifacts, starting new PipelineRun", "run", pipelineRunName, "success", pipelineRun.succeeded)
 			}
 		}
 	}
 
-	logger.Info("Creating a PipelineRun request for release", "release", rel.TagName)
-	run, err := r.PipelineRunner.CreatePipelineRun(ctx, *pipelineRunRequest)
+	if len(data.Spec.Params) > 0 {
+		releaseData := tekton.NewReleaseData(data.Spec.Owner, data.Spec.Repository, rel, getMissingArtifacts(data, rel.TagName), func() (string, error) {
+			resolver, ok := gitClient.(TagResolver)
+			if !ok {
+				return "", errors.New("provider does not support resolving tags")
+			}
+			return resolver.ResolveTag(ctx, data.Spec.Owner, data.Spec.Repository, rel.TagName)
+		})
+
+		params, err := tekton.RenderParams(data.Spec.Params, releaseData)
+		if err != nil {
+			metrics.PipelineRunCreationErrors.WithLabelValues(data.Spec.Owner, data.Spec.Repository, rel.TagName).Inc()
+			return 0, fmt.Errorf("could not render params for release %s: %w", rel.TagName, err)
+		}
+		pipelineRunRequest.RenderedParams = params
+	}
+
+	if pipeline != nil {
+		if dropped := tekton.DropUndeclaredParams(pipelineRunRequest, *pipeline); len(dropped) > 0 {
+			logger.Info("Dropped params not declared by the pipeline", "pipeline", pipelineRunRequest.PipelineName, "params", dropped)
+		}
+		if pipelineRunRequest.Target == gollumv1alpha1.BuildTargetPipelineSpec {
+			pipelineRunRequest.PipelineSpec = pipeline
+		}
+	}
+
+	logger.Info("Creating a PipelineRun request for release", "release", rel.TagName, "target", pipelineRunRequest.Target)
+	runName, runKind, err := r.startRun(ctx, *pipelineRunRequest)
 	if err != nil {
 		metrics.PipelineRunCreationErrors.WithLabelValues(data.Spec.Owner, data.Spec.Repository, rel.TagName).Inc()
 		return 0, err
@@ -347,19 +741,38 @@ func (r *RepositoryReconciler) createRun(ctx context.Context, namespace string,
 		statusRun.MostRecentRuns[artifactType] = &gollumv1alpha1.PipelineRun{}
 	}
 	statusRun.MostRecentRuns[artifactType].RunsCreated += 1
-	statusRun.MostRecentRuns[artifactType].Name = run.Name
+	statusRun.MostRecentRuns[artifactType].Name = runName
+	statusRun.MostRecentRuns[artifactType].Kind = runKind
 	statusRun.MostRecentRuns[artifactType].CreationTimestamp = metav1.Time{Time: time.Now()}
 
-	r.Recorder.Event(data, v1.EventTypeNormal, "PipelineRunScheduled", fmt.Sprintf("Scheduled PipelineRun %s (#%d) for tag %s", run.Name, statusRun.MostRecentRuns[artifactType].RunsCreated, rel.TagName))
+	r.Recorder.Event(data, v1.EventTypeNormal, "PipelineRunScheduled", fmt.Sprintf("Scheduled %s %s (#%d) for tag %s", runKind, runName, statusRun.MostRecentRuns[artifactType].RunsCreated, rel.TagName))
 	return statusRun.MostRecentRuns[artifactType].RunsCreated, nil
 }
 
-func (r *RepositoryReconciler) fetchArtifactDataForReleases(ctx context.Context, data *gollumv1alpha1.Repository, releases []github.Release) ([]ReleaseArtifacts, time.Duration) {
+// getMissingArtifacts returns the sorted artifact types that are missing for the tag.
+func getMissingArtifacts(data *gollumv1alpha1.Repository, tag string) []string {
+	var ret []string
+	if release, found := data.Status.Releases[tag]; found && release != nil {
+		for artifactType, missing := range release.MissingArtifacts {
+			if missing {
+				ret = append(ret, string(artifactType))
+			}
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func (r *RepositoryReconciler) fetchArtifactDataForReleases(ctx context.Context, gitClient GithubClient, data *gollumv1alpha1.Repository, releases []github.Release) ([]ReleaseArtifacts, time.Duration) {
 	p := pool.NewWithResults[ReleaseArtifacts]().WithContext(ctx).WithMaxGoroutines(3)
 
 	for _, release := range releases {
+		lowPriority := isLowPriorityRelease(data, release.TagName)
 		p.Go(func(ctx context.Context) (ReleaseArtifacts, error) {
-			ret, err := r.fetchArtifactDataForRelease(ctx, data, release)
+			if lowPriority {
+				ctx = github.WithLowPriority(ctx)
+			}
+			ret, err := r.fetchArtifactDataForRelease(ctx, gitClient, data, release)
 			if err != nil {
 				log.FromContext(ctx).Error(err, "could not fetch artifact for release")
 			}
@@ -376,11 +789,11 @@ func (r *RepositoryReconciler) fetchArtifactDataForReleases(ctx context.Context,
 		r.Recorder.Event(data, v1.EventTypeWarning, "UnauthorizedRequests", "Could not fetch data from GitHub Packages API")
 	}
 
-	var rateLimitErr *github.RateLimitError
 	var requeueAfter time.Duration
-	if errors.As(err, &rateLimitErr) {
-		requeueAfter = requeue.JitterFixAdditive(r.Requeue.Requeue(time.Until(rateLimitErr.Info.Reset)), 60)
-		r.Recorder.Event(data, v1.EventTypeWarning, "RateLimitExceeded", "Could not fetch artifacts from GitHub API")
+	retryAt, isRateLimited := github.RetryAt(err)
+	if isRateLimited {
+		requeueAfter = requeue.JitterFixAdditive(r.Requeue.Requeue(time.Until(retryAt)), 60)
+		r.Recorder.Event(data, v1.EventTypeWarning, getRateLimitReason(err), "Could not fetch artifacts from GitHub API")
 	}
 
 	meta.SetStatusCondition(data.GetConditions(), metav1.Condition{
@@ -390,7 +803,7 @@ func (r *RepositoryReconciler) fetchArtifactDataForReleases(ctx context.Context,
 		Message: "Fetching release artifacts from GitHub failed",
 	})
 
-	if err != nil && !errors.Is(err, github.ErrUnauthorized) && !errors.As(err, &rateLimitErr) {
+	if err != nil && !errors.Is(err, github.ErrUnauthorized) && !isRateLimited {
 		r.Recorder.Event(data, v1.EventTypeWarning, "FetchArtifactsError", "Received non-fatal errors while fetching artifact data")
 		log.FromContext(ctx).Error(err, "fetching artifact data produced error(s)")
 	}
@@ -405,7 +818,7 @@ func (r *RepositoryReconciler) fetchArtifactDataForReleases(ctx context.Context,
 	return ret, requeueAfter
 }
 
-func (r *RepositoryReconciler) fetchArtifactDataForRelease(ctx context.Context, data *gollumv1alpha1.Repository, release github.Release) (*ReleaseArtifacts, error) {
+func (r *RepositoryReconciler) fetchArtifactDataForRelease(ctx context.Context, gitClient GithubClient, data *gollumv1alpha1.Repository, release github.Release) (*ReleaseArtifacts, error) {
 	relWithArtifacts := &ReleaseArtifacts{
 		Release: release,
 	}
@@ -422,7 +835,7 @@ func (r *RepositoryReconciler) fetchArtifactDataForRelease(ctx context.Context,
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			relWithArtifacts.Assets, err = r.GithubClient.GetAssets(ctx, query)
+			relWithArtifacts.Assets, err = gitClient.GetAssets(ctx, query)
 			if err != nil {
 				fatalErrChan <- err
 			}
@@ -434,7 +847,7 @@ func (r *RepositoryReconciler) fetchArtifactDataForRelease(ctx context.Context,
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			relWithArtifacts.Packages, err = r.GithubClient.GetPackages(ctx, query)
+			relWithArtifacts.Packages, err = gitClient.GetPackages(ctx, query)
 			if err != nil {
 				fatalErrChan <- err
 			}
@@ -493,13 +906,13 @@ func (r *RepositoryReconciler) applyVersionFilter(ctx context.Context, data *gol
 	return filteredReleases, errs
 }
 
-func (r *RepositoryReconciler) createPipelineRunsForReleases(ctx context.Context, data *gollumv1alpha1.Repository, releases []ReleaseArtifacts, namespace string) (time.Duration, error) {
+func (r *RepositoryReconciler) createPipelineRunsForReleases(ctx context.Context, gitClient GithubClient, pipelines map[gollumv1alpha1.ArtifactType]*pipelinev1.PipelineSpec, data *gollumv1alpha1.Repository, releases []ReleaseArtifacts, namespace string) (time.Duration, error) {
 	var err error
 	var maxPreviouslyCreatedRuns int
 	var runsCreated int
 
 	for _, rel := range releases {
-		totalRunsCreatedForVersion, createRunErr := r.createRunsForRelease(ctx, namespace, data, rel.Release)
+		totalRunsCreatedForVersion, createRunErr := r.createRunsForRelease(ctx, gitClient, pipelines, namespace, data, rel.Release)
 		if createRunErr != nil {
 			err = multierror.Append(err, createRunErr)
 		} else {
@@ -542,12 +955,61 @@ func (r *RepositoryReconciler) createPipelineRunsForReleases(ctx context.Context
 	return requeue.JitterFixAdditive(r.Requeue.Requeue(requeue.ExponentialBackoff(maxPreviouslyCreatedRuns, 5)), 120), err
 }
 
+// findRepositoriesForSecret returns reconcile requests for all Repositories that reference the given Secret as their
+// credentials, so rotated credentials are picked up immediately.
+func (r *RepositoryReconciler) findRepositoriesForSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	repos := &gollumv1alpha1.RepositoryList{}
+	if err := r.List(ctx, repos, client.InNamespace(secret.GetNamespace()), client.MatchingFields{credentialsSecretRefField: secret.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "could not list repositories for secret", "secret", secret.GetName())
+		return nil
+	}
+
+	ret := make([]reconcile.Request, 0, len(repos.Items))
+	for _, repo := range repos.Items {
+		ret = append(ret, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: repo.Namespace, Name: repo.Name}})
+	}
+	return ret
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *RepositoryReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.Recorder = mgr.GetEventRecorderFor("repository-controller")
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &gollumv1alpha1.Repository{}, credentialsSecretRefField, func(obj client.Object) []string {
+		repo := obj.(*gollumv1alpha1.Repository)
+		if repo.Spec.CredentialsSecretRef == nil || repo.Spec.CredentialsSecretRef.Name == "" {
+			return nil
+		}
+		return []string{repo.Spec.CredentialsSecretRef.Name}
+	}); err != nil {
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &gollumv1alpha1.Repository{}, ownerRepoField, func(obj client.Object) []string {
+		repo := obj.(*gollumv1alpha1.Repository)
+		return []string{getOwnerRepoKey(repo.Spec.Owner, repo.Spec.Repository)}
+	}); err != nil {
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &gollumv1alpha1.Repository{}, buildProfileRefField, func(obj client.Object) []string {
+		repo := obj.(*gollumv1alpha1.Repository)
+		if repo.Spec.BuildProfileRef == nil || repo.Spec.BuildProfileRef.Name == "" {
+			return nil
+		}
+		return []string{repo.Spec.BuildProfileRef.Name}
+	}); err != nil {
+		return err
+	}
+
+	r.triggers = make(chan event.GenericEvent, triggerBufferSize)
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&gollumv1alpha1.Repository{}).
+		For(&gollumv1alpha1.Repository{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// only the metadata of Secrets is watched, so the data of all Secrets in the cluster is not cached
+		WatchesMetadata(&v1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.findRepositoriesForSecret)).
+		Watches(&gollumv1alpha1.BuildProfile{}, handler.EnqueueRequestsFromMapFunc(r.findRepositoriesForBuildProfile)).
+		WatchesRawSource(source.Channel(r.triggers, &handler.EnqueueRequestForObject{})).
 		Named("repository").
-		WithEventFilter(predicate.GenerationChangedPredicate{}).
 		Complete(r)
 }
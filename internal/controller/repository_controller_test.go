@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type identityRequeue struct{}
+
+func (identityRequeue) Requeue(duration time.Duration) time.Duration {
+	return duration
+}
+
+func TestGetPollRequeueAfter_InvalidSchedule(t *testing.T) {
+	reconciler := &RepositoryReconciler{
+		Requeue:                identityRequeue{},
+		DefaultRequeueInterval: time.Hour,
+	}
+
+	repo := &gollumv1alpha1.Repository{}
+	repo.Spec.Schedule = &gollumv1alpha1.ScheduleSpec{
+		Cron:     "0 0 * *",
+		Interval: &metav1.Duration{Duration: 10 * time.Minute},
+	}
+
+	// the interval is used as fallback
+	if got := reconciler.getPollRequeueAfter(repo); got != 10*time.Minute {
+		t.Errorf("getPollRequeueAfter() = %v, want fallback interval", got)
+	}
+	if !meta.IsStatusConditionTrue(repo.Status.Conditions, "InvalidSchedule") {
+		t.Fatalf("expected InvalidSchedule condition, got %v", repo.Status.Conditions)
+	}
+
+	repo.Spec.Schedule.Cron = "*/5 * * * *"
+	if got := reconciler.getPollRequeueAfter(repo); got <= 0 || got > 5*time.Minute {
+		t.Errorf("getPollRequeueAfter() = %v, want next cron activation", got)
+	}
+	if meta.FindStatusCondition(repo.Status.Conditions, "InvalidSchedule") != nil {
+		t.Errorf("expected InvalidSchedule condition to be removed, got %v", repo.Status.Conditions)
+	}
+}
+
+func TestGetPollRequeueAfter_ClearsLearnedInterval(t *testing.T) {
+	reconciler := &RepositoryReconciler{
+		Requeue:                identityRequeue{},
+		DefaultRequeueInterval: time.Hour,
+	}
+
+	tests := []struct {
+		name     string
+		schedule *gollumv1alpha1.ScheduleSpec
+	}{
+		{name: "cron", schedule: &gollumv1alpha1.ScheduleSpec{Cron: "*/5 * * * *"}},
+		{name: "interval", schedule: &gollumv1alpha1.ScheduleSpec{Interval: &metav1.Duration{Duration: 10 * time.Minute}}},
+		{name: "no cadence"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &gollumv1alpha1.Repository{}
+			repo.Spec.Schedule = tt.schedule
+			repo.Status.PollInterval = &metav1.Duration{Duration: 6 * time.Hour}
+
+			reconciler.getPollRequeueAfter(repo)
+			if repo.Status.PollInterval != nil {
+				t.Errorf("expected learned interval to be cleared, got %v", repo.Status.PollInterval)
+			}
+		})
+	}
+}
+
+type fakeBuildWindow struct {
+	opensIn time.Duration
+}
+
+func (w fakeBuildWindow) NextActive(t time.Time) (time.Time, error) {
+	return t.Add(w.opensIn), nil
+}
+
+func TestIsPendingBuildWindow(t *testing.T) {
+	tests := []struct {
+		name          string
+		buildWindow   BuildWindow
+		spec          *gollumv1alpha1.BuildWindowSpec
+		wantPending   bool
+		wantCondition bool
+	}{
+		{
+			name: "no build window",
+		},
+		{
+			name:        "global build window open",
+			buildWindow: fakeBuildWindow{},
+		},
+		{
+			name:        "global build window closed",
+			buildWindow: fakeBuildWindow{opensIn: time.Hour},
+			wantPending: true,
+		},
+		{
+			name:        "repository build window takes precedence",
+			buildWindow: fakeBuildWindow{opensIn: time.Hour},
+			spec:        &gollumv1alpha1.BuildWindowSpec{Windows: []string{"00:00-24:00"}},
+		},
+		{
+			name:          "invalid repository build window falls back to global build window",
+			buildWindow:   fakeBuildWindow{opensIn: time.Hour},
+			spec:          &gollumv1alpha1.BuildWindowSpec{Windows: []string{"25:00-26:00"}},
+			wantPending:   true,
+			wantCondition: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reconciler := &RepositoryReconciler{BuildWindow: tt.buildWindow}
+
+			// a previously reported invalid build window is cleared once it is valid
+			repo := &gollumv1alpha1.Repository{}
+			repo.Spec.BuildWindow = tt.spec
+			setInvalidBuildWindowCondition(repo, errors.New("invalid"))
+
+			opensAt, pending := reconciler.isPendingBuildWindow(repo)
+			if pending != tt.wantPending {
+				t.Errorf("isPendingBuildWindow() pending = %v, want %v", pending, tt.wantPending)
+			}
+			if pending && time.Until(opensAt) <= 0 {
+				t.Errorf("isPendingBuildWindow() opensAt = %v, expected time in the future", opensAt)
+			}
+			if gotCondition := meta.IsStatusConditionTrue(repo.Status.Conditions, "InvalidBuildWindow"); gotCondition != tt.wantCondition {
+				t.Errorf("isPendingBuildWindow() InvalidBuildWindow condition = %v, want %v", gotCondition, tt.wantCondition)
+			}
+		})
+	}
+}
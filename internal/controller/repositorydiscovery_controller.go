@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+	"github.com/soerenschneider/gollum/internal/github"
+	"github.com/soerenschneider/gollum/internal/requeue"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	repositoryDiscoveryKind         = "RepositoryDiscovery"
+	defaultRepositoryDiscoveryEvery = time.Hour
+)
+
+type RepositoryLister interface {
+	ListRepositories(ctx context.Context, owner string) ([]github.Repository, error)
+}
+
+// RepositoryDiscoveryReconciler reconciles a RepositoryDiscovery object
+type RepositoryDiscoveryReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	Recorder         record.EventRecorder
+	RepositoryLister RepositoryLister
+}
+
+// +kubebuilder:rbac:groups=gollum.soeren.cloud,resources=repositorydiscoveries,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gollum.soeren.cloud,resources=repositorydiscoveries/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=gollum.soeren.cloud,resources=repositorydiscoveries/finalizers,verbs=update
+func (r *RepositoryDiscoveryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	data := &gollumv1alpha1.RepositoryDiscovery{}
+	if err := r.Get(ctx, req.NamespacedName, data); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	logger := log.FromContext(ctx)
+	defer func() {
+		if err := r.Status().Update(ctx, data); err != nil {
+			logger.Error(err, "could not update status")
+		}
+	}()
+
+	interval := defaultRepositoryDiscoveryEvery
+	if data.Spec.Interval != nil && data.Spec.Interval.Duration > 0 {
+		interval = data.Spec.Interval.Duration
+	}
+
+	filter, err := newDiscoveryFilter(data.Spec.Filter)
+	if err != nil {
+		setDiscoveryCondition(data, metav1.ConditionFalse, "InvalidFilter", err.Error())
+		return ctrl.Result{}, nil
+	}
+
+	// the repositories of the owner are only listed once per interval or if the spec changed, generated Repositories
+	// that have been modified are restored using the last result
+	isOutdated := data.Status.LastDiscovery == nil || time.Since(data.Status.LastDiscovery.Time) >= interval
+	if isOutdated || data.Status.ObservedGeneration != data.Generation {
+		repos, err := r.RepositoryLister.ListRepositories(ctx, data.Spec.Owner)
+		if err != nil {
+			requeueAfter := requeue.JitterPercentageDistributed(interval, 10)
+			if retryAt, isRateLimited := github.RetryAt(err); isRateLimited {
+				requeueAfter = requeue.JitterFixAdditive(time.Until(retryAt), 10)
+			}
+			logger.Error(err, "could not list repositories", "owner", data.Spec.Owner, "requeue_after", requeueAfter)
+			setDiscoveryCondition(data, metav1.ConditionFalse, "DiscoveryFailed", err.Error())
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+
+		data.Status.Repositories = filter.apply(repos)
+		data.Status.LastDiscovery = &metav1.Time{Time: time.Now()}
+		data.Status.ObservedGeneration = data.Generation
+	}
+
+	desired := make([]gollumv1alpha1.Repository, 0, len(data.Status.Repositories))
+	for _, ownerRepo := range data.Status.Repositories {
+		owner, repo, _ := strings.Cut(ownerRepo, "/")
+		desired = append(desired, renderRepository(data, data.Spec.Template, owner, repo))
+	}
+
+	prune := data.Spec.Prune == nil || *data.Spec.Prune
+	changed, skipped, err := reconcileGeneratedRepositories(ctx, r.Client, r.Scheme, data, repositoryDiscoveryKind, desired, prune)
+	setSkippedCondition(&data.Status.Conditions, data.Generation, skipped)
+	if err != nil {
+		logger.Error(err, "could not reconcile generated repositories")
+		r.Recorder.Event(data, v1.EventTypeWarning, "GenerateRepositoriesFailed", err.Error())
+		setDiscoveryCondition(data, metav1.ConditionFalse, "GenerateRepositoriesFailed", err.Error())
+	} else {
+		setDiscoveryCondition(data, metav1.ConditionTrue, "RepositoriesGenerated", fmt.Sprintf("Discovered %d repositories", len(desired)))
+	}
+	if changed > 0 {
+		r.Recorder.Event(data, v1.EventTypeNormal, "RepositoriesGenerated", fmt.Sprintf("Created, updated or pruned %d repositories", changed))
+	}
+
+	requeueAfter := time.Until(data.Status.LastDiscovery.Add(interval))
+	return ctrl.Result{RequeueAfter: requeue.JitterPercentageAdditive(max(requeueAfter, time.Minute), 10)}, nil
+}
+
+func setDiscoveryCondition(data *gollumv1alpha1.RepositoryDiscovery, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&data.Status.Conditions, metav1.Condition{
+		Type:               "Ready",
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: data.Generation,
+	})
+}
+
+type discoveryFilter struct {
+	spec      gollumv1alpha1.DiscoveryFilter
+	nameRegex *regexp.Regexp
+}
+
+func newDiscoveryFilter(spec *gollumv1alpha1.DiscoveryFilter) (*discoveryFilter, error) {
+	ret := &discoveryFilter{}
+	if spec == nil {
+		return ret, nil
+	}
+
+	ret.spec = *spec
+	if spec.NameRegex != "" {
+		nameRegex, err := regexp.Compile(spec.NameRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid name regex: %w", err)
+		}
+		ret.nameRegex = nameRegex
+	}
+	return ret, nil
+}
+
+func (f *discoveryFilter) matches(repo github.Repository) bool {
+	if repo.Archived && !f.spec.IncludeArchived {
+		return false
+	}
+	if repo.Fork && !f.spec.IncludeForks {
+		return false
+	}
+	if f.nameRegex != nil && !f.nameRegex.MatchString(repo.Name) {
+		return false
+	}
+	if len(f.spec.Languages) > 0 && !slices.ContainsFunc(f.spec.Languages, func(language string) bool {
+		return strings.EqualFold(language, repo.Language)
+	}) {
+		return false
+	}
+	if len(f.spec.Topics) > 0 && !slices.ContainsFunc(f.spec.Topics, func(topic string) bool {
+		return slices.Contains(repo.Topics, strings.ToLower(topic))
+	}) {
+		return false
+	}
+	return true
+}
+
+// apply returns the matching repositories in the form "owner/repo", sorted by name.
+func (f *discoveryFilter) apply(repos []github.Repository) []string {
+	ret := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		if f.matches(repo) {
+			ret = append(ret, fmt.Sprintf("%s/%s", repo.Owner.Login, repo.Name))
+		}
+	}
+	slices.Sort(ret)
+	return ret
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RepositoryDiscoveryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.Recorder = mgr.GetEventRecorderFor("repositorydiscovery-controller")
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&gollumv1alpha1.RepositoryDiscovery{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&gollumv1alpha1.Repository{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Named("repositorydiscovery").
+		Complete(r)
+}
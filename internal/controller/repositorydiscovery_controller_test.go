@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"slices"
+	"testing"
+
+	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+	"github.com/soerenschneider/gollum/internal/github"
+)
+
+func TestDiscoveryFilter(t *testing.T) {
+	newRepo := func(name, language string, archived, fork bool, topics ...string) github.Repository {
+		repo := github.Repository{Name: name, Language: language, Archived: archived, Fork: fork, Topics: topics}
+		repo.Owner.Login = "owner"
+		return repo
+	}
+	repos := []github.Repository{
+		newRepo("operator", "Go", false, false, "kubernetes", "operator"),
+		newRepo("website", "TypeScript", false, false),
+		newRepo("legacy", "Go", true, false),
+		newRepo("forked-cli", "Go", false, true, "cli"),
+		newRepo("cli", "go", false, false, "cli"),
+	}
+
+	tests := []struct {
+		name    string
+		spec    *gollumv1alpha1.DiscoveryFilter
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "no filter skips archived repositories and forks",
+			want: []string{"owner/cli", "owner/operator", "owner/website"},
+		},
+		{
+			name: "include archived repositories and forks",
+			spec: &gollumv1alpha1.DiscoveryFilter{IncludeArchived: true, IncludeForks: true},
+			want: []string{"owner/cli", "owner/forked-cli", "owner/legacy", "owner/operator", "owner/website"},
+		},
+		{
+			name: "languages are case insensitive",
+			spec: &gollumv1alpha1.DiscoveryFilter{Languages: []string{"GO"}},
+			want: []string{"owner/cli", "owner/operator"},
+		},
+		{
+			name: "any topic matches",
+			spec: &gollumv1alpha1.DiscoveryFilter{Topics: []string{"Operator", "cli"}, IncludeForks: true},
+			want: []string{"owner/cli", "owner/forked-cli", "owner/operator"},
+		},
+		{
+			name: "name regex",
+			spec: &gollumv1alpha1.DiscoveryFilter{NameRegex: "^(cli|web.*)$"},
+			want: []string{"owner/cli", "owner/website"},
+		},
+		{
+			name:    "invalid name regex",
+			spec:    &gollumv1alpha1.DiscoveryFilter{NameRegex: "("},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := newDiscoveryFilter(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newDiscoveryFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if got := filter.apply(repos); !slices.Equal(got, tt.want) {
+				t.Errorf("apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	repositorySetKind            = "RepositorySet"
+	defaultRepositorySetEntryKey = "repositories"
+	configMapRefField            = ".spec.configMapRef.name"
+)
+
+// RepositorySetReconciler reconciles a RepositorySet object
+type RepositorySetReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=gollum.soeren.cloud,resources=repositorysets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gollum.soeren.cloud,resources=repositorysets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=gollum.soeren.cloud,resources=repositorysets/finalizers,verbs=update
+func (r *RepositorySetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	data := &gollumv1alpha1.RepositorySet{}
+	if err := r.Get(ctx, req.NamespacedName, data); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	logger := log.FromContext(ctx)
+	defer func() {
+		if err := r.Status().Update(ctx, data); err != nil {
+			logger.Error(err, "could not update status")
+		}
+	}()
+
+	entries, err := r.getEntries(ctx, data)
+	if err != nil {
+		logger.Error(err, "could not get entries")
+		setRepositorySetCondition(data, metav1.ConditionFalse, "InvalidEntries", err.Error())
+		// changes to the ConfigMap trigger a reconcile
+		return ctrl.Result{}, nil
+	}
+
+	desired := make([]gollumv1alpha1.Repository, 0, len(entries))
+	data.Status.Repositories = make([]string, 0, len(entries))
+	for _, entry := range entries {
+		repo, err := renderRepositorySetEntry(data, entry)
+		if err != nil {
+			setRepositorySetCondition(data, metav1.ConditionFalse, "InvalidOverrides", err.Error())
+			return ctrl.Result{}, nil
+		}
+		desired = append(desired, repo)
+		data.Status.Repositories = append(data.Status.Repositories, fmt.Sprintf("%s/%s", entry.Owner, entry.Repository))
+	}
+
+	prune := data.Spec.Prune == nil || *data.Spec.Prune
+	changed, skipped, err := reconcileGeneratedRepositories(ctx, r.Client, r.Scheme, data, repositorySetKind, desired, prune)
+	setSkippedCondition(&data.Status.Conditions, data.Generation, skipped)
+	if changed > 0 {
+		r.Recorder.Event(data, v1.EventTypeNormal, "RepositoriesGenerated", fmt.Sprintf("Created, updated or pruned %d repositories", changed))
+	}
+	if err != nil {
+		logger.Error(err, "could not reconcile generated repositories")
+		r.Recorder.Event(data, v1.EventTypeWarning, "GenerateRepositoriesFailed", err.Error())
+		setRepositorySetCondition(data, metav1.ConditionFalse, "GenerateRepositoriesFailed", err.Error())
+		return ctrl.Result{}, err
+	}
+
+	setRepositorySetCondition(data, metav1.ConditionTrue, "RepositoriesGenerated", fmt.Sprintf("Generated %d repositories", len(desired)-len(skipped)))
+	return ctrl.Result{}, nil
+}
+
+// getEntries returns the entries of the spec, followed by the entries of the referenced ConfigMap.
+func (r *RepositorySetReconciler) getEntries(ctx context.Context, data *gollumv1alpha1.RepositorySet) ([]gollumv1alpha1.RepositorySetEntry, error) {
+	entries := data.Spec.Repositories
+
+	if ref := data.Spec.ConfigMapRef; ref != nil {
+		configMap := &v1.ConfigMap{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: data.Namespace, Name: ref.Name}, configMap); err != nil {
+			return nil, fmt.Errorf("could not get configmap %q: %w", ref.Name, err)
+		}
+
+		key := ref.Key
+		if key == "" {
+			key = defaultRepositorySetEntryKey
+		}
+
+		var configMapEntries []gollumv1alpha1.RepositorySetEntry
+		if err := yaml.Unmarshal([]byte(configMap.Data[key]), &configMapEntries); err != nil {
+			return nil, fmt.Errorf("could not parse key %q of configmap %q: %w", key, ref.Name, err)
+		}
+		entries = append(entries, configMapEntries...)
+	}
+
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		if entry.Owner == "" || entry.Repository == "" {
+			return nil, fmt.Errorf("entry %s/%s is missing owner or repo", entry.Owner, entry.Repository)
+		}
+		name := getGeneratedRepositoryName(entry.Owner, entry.Repository)
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate entry %s/%s", entry.Owner, entry.Repository)
+		}
+		seen[name] = true
+	}
+
+	return entries, nil
+}
+
+// renderRepositorySetEntry renders the template and merges the overrides of the entry into its spec.
+func renderRepositorySetEntry(data *gollumv1alpha1.RepositorySet, entry gollumv1alpha1.RepositorySetEntry) (gollumv1alpha1.Repository, error) {
+	template := *data.Spec.Template.DeepCopy()
+	if entry.Overrides != nil && len(entry.Overrides.Raw) > 0 {
+		merged, err := mergeRepositoryConfig(template.Spec, entry.Overrides.Raw)
+		if err != nil {
+			return gollumv1alpha1.Repository{}, fmt.Errorf("could not apply overrides of %s/%s: %w", entry.Owner, entry.Repository, err)
+		}
+		template.Spec = merged
+	}
+
+	return renderRepository(data, template, entry.Owner, entry.Repository), nil
+}
+
+// mergeRepositoryConfig applies the overrides to the config as a JSON merge patch (RFC 7386).
+func mergeRepositoryConfig(config gollumv1alpha1.RepositoryConfig, overrides []byte) (gollumv1alpha1.RepositoryConfig, error) {
+	original, err := json.Marshal(config)
+	if err != nil {
+		return config, err
+	}
+
+	var target, patch any
+	if err := json.Unmarshal(original, &target); err != nil {
+		return config, err
+	}
+	if err := json.Unmarshal(overrides, &patch); err != nil {
+		return config, err
+	}
+
+	merged, err := json.Marshal(mergePatch(target, patch))
+	if err != nil {
+		return config, err
+	}
+
+	ret := gollumv1alpha1.RepositoryConfig{}
+	if err := json.Unmarshal(merged, &ret); err != nil {
+		return config, err
+	}
+	return ret, nil
+}
+
+func mergePatch(target, patch any) any {
+	patchMap, isMap := patch.(map[string]any)
+	if !isMap {
+		return patch
+	}
+
+	targetMap, isMap := target.(map[string]any)
+	if !isMap {
+		targetMap = map[string]any{}
+	}
+
+	for key, value := range patchMap {
+		if value == nil {
+			delete(targetMap, key)
+		} else {
+			targetMap[key] = mergePatch(targetMap[key], value)
+		}
+	}
+	return targetMap
+}
+
+func setRepositorySetCondition(data *gollumv1alpha1.RepositorySet, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&data.Status.Conditions, metav1.Condition{
+		Type:               "Ready",
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: data.Generation,
+	})
+}
+
+func (r *RepositorySetReconciler) findRepositorySetsForConfigMap(ctx context.Context, configMap client.Object) []reconcile.Request {
+	sets := &gollumv1alpha1.RepositorySetList{}
+	if err := r.List(ctx, sets, client.InNamespace(configMap.GetNamespace()), client.MatchingFields{configMapRefField: configMap.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "could not list repository sets for configmap", "configmap", configMap.GetName())
+		return nil
+	}
+
+	ret := make([]reconcile.Request, 0, len(sets.Items))
+	for _, set := range sets.Items {
+		ret = append(ret, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: set.Namespace, Name: set.Name}})
+	}
+	return ret
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RepositorySetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.Recorder = mgr.GetEventRecorderFor("repositoryset-controller")
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &gollumv1alpha1.RepositorySet{}, configMapRefField, func(obj client.Object) []string {
+		set := obj.(*gollumv1alpha1.RepositorySet)
+		if set.Spec.ConfigMapRef == nil || set.Spec.ConfigMapRef.Name == "" {
+			return nil
+		}
+		return []string{set.Spec.ConfigMapRef.Name}
+	}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&gollumv1alpha1.RepositorySet{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&gollumv1alpha1.Repository{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&v1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.findRepositorySetsForConfigMap)).
+		Named("repositoryset").
+		Complete(r)
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		patch  string
+		want   string
+	}{
+		{
+			name:   "add and replace keys",
+			target: `{"a": "b", "c": "d"}`,
+			patch:  `{"a": "z", "e": "f"}`,
+			want:   `{"a": "z", "c": "d", "e": "f"}`,
+		},
+		{
+			name:   "null removes keys",
+			target: `{"a": "b", "c": "d"}`,
+			patch:  `{"a": null}`,
+			want:   `{"c": "d"}`,
+		},
+		{
+			name:   "nested objects are merged",
+			target: `{"a": {"b": "c", "d": "e"}}`,
+			patch:  `{"a": {"b": "x", "d": null}}`,
+			want:   `{"a": {"b": "x"}}`,
+		},
+		{
+			name:   "arrays are replaced",
+			target: `{"a": [1, 2]}`,
+			patch:  `{"a": [3]}`,
+			want:   `{"a": [3]}`,
+		},
+		{
+			name:   "object replaces scalar",
+			target: `{"a": "b"}`,
+			patch:  `{"a": {"c": "d"}}`,
+			want:   `{"a": {"c": "d"}}`,
+		},
+		{
+			name:   "scalar patch replaces target",
+			target: `{"a": "b"}`,
+			patch:  `"c"`,
+			want:   `"c"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decode := func(raw string) any {
+				var ret any
+				if err := json.Unmarshal([]byte(raw), &ret); err != nil {
+					t.Fatal(err)
+				}
+				return ret
+			}
+
+			want := decode(tt.want)
+			if got := mergePatch(decode(tt.target), decode(tt.patch)); !reflect.DeepEqual(got, want) {
+				t.Errorf("mergePatch() = %v, want %v", got, want)
+			}
+		})
+	}
+}
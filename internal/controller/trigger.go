@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+const (
+	ownerRepoField = ".spec.ownerRepo"
+
+	// triggerBufferSize is the amount of triggered reconciles that may be queued before TriggerReconcile blocks.
+	triggerBufferSize = 100
+)
+
+// triggeredRepositories keeps track of the Repositories that have been triggered but not been checked yet.
+type triggeredRepositories struct {
+	mutex sync.Mutex
+	repos map[types.NamespacedName]struct{}
+}
+
+func (t *triggeredRepositories) add(repo types.NamespacedName) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.repos == nil {
+		t.repos = map[types.NamespacedName]struct{}{}
+	}
+	t.repos[repo] = struct{}{}
+}
+
+// consume returns whether the Repository has been triggered and forgets about the trigger.
+func (t *triggeredRepositories) consume(repo types.NamespacedName) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	_, found := t.repos[repo]
+	delete(t.repos, repo)
+	return found
+}
+
+// watchedRepositories maps Repositories to the owner and name of the repository they watch, so state that is kept for
+// the repository can be dropped once the Repository has been deleted.
+type watchedRepositories struct {
+	mutex sync.Mutex
+	repos map[types.NamespacedName][2]string
+}
+
+func (w *watchedRepositories) set(repo types.NamespacedName, owner, name string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.repos == nil {
+		w.repos = map[types.NamespacedName][2]string{}
+	}
+	w.repos[repo] = [2]string{owner, name}
+}
+
+// remove returns the owner and name of the repository the Repository watched and forgets about the Repository.
+func (w *watchedRepositories) remove(repo types.NamespacedName) (string, string, bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	ownerRepo, found := w.repos[repo]
+	delete(w.repos, repo)
+	return ownerRepo[0], ownerRepo[1], found
+}
+
+// isGithubRepository returns whether the Repository watches a repository hosted on GitHub.
+func isGithubRepository(data *gollumv1alpha1.Repository) bool {
+	provider := data.Spec.Provider
+	return provider == nil || provider.Type == "" || provider.Type == gollumv1alpha1.ProviderGithub
+}
+
+func getOwnerRepoKey(owner, repo string) string {
+	return strings.ToLower(fmt.Sprintf("%s/%s", owner, repo))
+}
+
+// TriggerReconcile immediately reconciles all Repositories that watch the given GitHub repository, e.g. after a
+// webhook reported a new release. Repositories of other providers are skipped. Triggered Repositories are checked
+// regardless of detected changes. It returns the amount of Repositories that have been triggered.
+func (r *RepositoryReconciler) TriggerReconcile(ctx context.Context, owner, repo string) (int, error) {
+	if r.triggers == nil {
+		return 0, fmt.Errorf("controller not set up")
+	}
+
+	repos := &gollumv1alpha1.RepositoryList{}
+	if err := r.List(ctx, repos, client.MatchingFields{ownerRepoField: getOwnerRepoKey(owner, repo)}); err != nil {
+		return 0, fmt.Errorf("could not list repositories for %s/%s: %w", owner, repo, err)
+	}
+
+	triggered := 0
+	for idx := range repos.Items {
+		if !isGithubRepository(&repos.Items[idx]) {
+			continue
+		}
+
+		r.triggered.add(client.ObjectKeyFromObject(&repos.Items[idx]))
+		select {
+		case <-ctx.Done():
+			return triggered, ctx.Err()
+		case r.triggers <- event.GenericEvent{Object: &repos.Items[idx]}:
+			triggered++
+		}
+	}
+
+	return triggered, nil
+}
@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestTriggeredRepositories(t *testing.T) {
+	repo := types.NamespacedName{Namespace: "builds", Name: "repo"}
+
+	triggered := &triggeredRepositories{}
+	if triggered.consume(repo) {
+		t.Fatal("expected repository not to be triggered")
+	}
+
+	triggered.add(repo)
+	triggered.add(repo)
+	if !triggered.consume(repo) {
+		t.Fatal("expected repository to be triggered")
+	}
+	if triggered.consume(repo) {
+		t.Fatal("expected trigger to be consumed")
+	}
+}
+
+// newIndexedClient returns a fake client that indexes Repositories by the repository they watch.
+func newIndexedClient(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := gollumv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).WithIndex(&gollumv1alpha1.Repository{}, ownerRepoField, func(obj client.Object) []string {
+		repo := obj.(*gollumv1alpha1.Repository)
+		return []string{getOwnerRepoKey(repo.Spec.Owner, repo.Spec.Repository)}
+	}).Build()
+}
+
+func newWatchingRepository(name, owner, repo string, provider gollumv1alpha1.ProviderType) *gollumv1alpha1.Repository {
+	ret := &gollumv1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Namespace: "builds", Name: name}}
+	ret.Spec.Owner = owner
+	ret.Spec.Repository = repo
+	if provider != "" {
+		ret.Spec.Provider = &gollumv1alpha1.ProviderSpec{Type: provider, URL: "https://gitea.example.com"}
+	}
+	return ret
+}
+
+func TestTriggerReconcile(t *testing.T) {
+	reconciler := &RepositoryReconciler{
+		Client: newIndexedClient(t,
+			newWatchingRepository("default-provider", "SoerenSchneider", "Gollum", ""),
+			newWatchingRepository("github", "soerenschneider", "gollum", gollumv1alpha1.ProviderGithub),
+			newWatchingRepository("gitea", "soerenschneider", "gollum", gollumv1alpha1.ProviderGitea),
+			newWatchingRepository("other", "soerenschneider", "other", ""),
+		),
+		triggers: make(chan event.GenericEvent, 10),
+	}
+
+	triggered, err := reconciler.TriggerReconcile(context.Background(), "soerenschneider", "gollum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if triggered != 2 {
+		t.Errorf("TriggerReconcile() = %d, want 2", triggered)
+	}
+
+	close(reconciler.triggers)
+	var names []string
+	for triggered := range reconciler.triggers {
+		names = append(names, triggered.Object.GetName())
+		if !reconciler.triggered.consume(client.ObjectKeyFromObject(triggered.Object)) {
+			t.Errorf("expected %q to bypass change detection", triggered.Object.GetName())
+		}
+	}
+	slices.Sort(names)
+	if !slices.Equal(names, []string{"default-provider", "github"}) {
+		t.Errorf("expected only GitHub repositories to be triggered, got %v", names)
+	}
+}
+
+type forgettingClient struct {
+	GithubClient
+	forgotten []string
+}
+
+func (c *forgettingClient) HasReleaseEvents(_ context.Context, _, _ string) (bool, error) {
+	return true, nil
+}
+
+func (c *forgettingClient) ConfirmReleaseEvents(_, _ string) {}
+
+func (c *forgettingClient) Forget(owner, repo string) {
+	c.forgotten = append(c.forgotten, owner+"/"+repo)
+}
+
+func TestForgetReleaseEvents(t *testing.T) {
+	gitClient := &forgettingClient{}
+	reconciler := &RepositoryReconciler{
+		Client:       newIndexedClient(t, newWatchingRepository("still-watching", "soerenschneider", "shared", "")),
+		GithubClient: gitClient,
+	}
+
+	deleted := types.NamespacedName{Namespace: "builds", Name: "deleted"}
+	deletedShared := types.NamespacedName{Namespace: "other", Name: "deleted"}
+	reconciler.watched.set(deleted, "soerenschneider", "gollum")
+	reconciler.watched.set(deletedShared, "soerenschneider", "shared")
+
+	reconciler.forgetReleaseEvents(context.Background(), deleted)
+	reconciler.forgetReleaseEvents(context.Background(), deletedShared)
+	// unknown repositories are ignored
+	reconciler.forgetReleaseEvents(context.Background(), deleted)
+
+	if !slices.Equal(gitClient.forgotten, []string{"soerenschneider/gollum"}) {
+		t.Errorf("expected only state of unwatched repository to be dropped, got %v", gitClient.forgotten)
+	}
+}
@@ -3,14 +3,25 @@ package controller
 import (
 	"cmp"
 	"errors"
+	"fmt"
 	"time"
 
 	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
 	"github.com/soerenschneider/gollum/internal/github"
+	"github.com/soerenschneider/gollum/internal/requeue"
 	"github.com/soerenschneider/gollum/internal/versionfilter"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// minScheduleInterval is the shortest interval a Repository's schedule may poll in.
+	minScheduleInterval = time.Minute
+
+	// fullCheckInterval is the longest time releases and artifacts are not fetched if changes are detected using
+	// events, as a safety net for missed events.
+	fullCheckInterval = 24 * time.Hour
+)
+
 func getVersionFilter(filter *gollumv1alpha1.VersionFilterSpec) (VersionFilter, error) {
 	if filter == nil {
 		return nil, errors.New("filter empty")
@@ -62,6 +73,14 @@ func getSatisfiedReleases(repo *gollumv1alpha1.Repository) []string {
 	return ret
 }
 
+// isLowPriorityRelease returns true if the release has been seen before and none of its artifacts are missing. Those
+// releases are only re-checked, which is not urgent and may be deferred to preserve quota, while releases with missing
+// artifacts are about to be built.
+func isLowPriorityRelease(data *gollumv1alpha1.Repository, tag string) bool {
+	_, isKnownRelease := data.Status.Releases[tag]
+	return isKnownRelease && len(getMissingArtifacts(data, tag)) == 0
+}
+
 func buildArtifactQuery(data *gollumv1alpha1.Repository, release github.Release) github.ArtifactQuery {
 	return github.ArtifactQuery{
 		Owner:   data.Spec.Owner,
@@ -70,6 +89,110 @@ func buildArtifactQuery(data *gollumv1alpha1.Repository, release github.Release)
 	}
 }
 
+// getRateLimitReason returns the reason that is used for conditions and events if err has been caused by a rate limit.
+func getRateLimitReason(err error) string {
+	var secondaryRateLimitErr *github.SecondaryRateLimitError
+	if errors.As(err, &secondaryRateLimitErr) {
+		return "SecondaryRateLimitExceeded"
+	}
+
+	var throttledErr *github.ThrottledError
+	if errors.As(err, &throttledErr) {
+		return "RateLimitReserveReached"
+	}
+	return "RateLimitExceeded"
+}
+
+// estimatePollCost estimates the amount of API requests a single poll of the repository costs: the paginated releases
+// plus one request per filtered release and artifact type. Memorized releases are still paginated, but not contained
+// in the given releases.
+func estimatePollCost(data *gollumv1alpha1.Repository, releases, filteredReleases []github.Release) int {
+	const releasesPerPage = 100
+	totalReleases := len(releases) + len(buildReleaseRequest(data).IgnoreReleases)
+	cost := max(1, (totalReleases+releasesPerPage-1)/releasesPerPage)
+
+	artifactTypes := 0
+	for _, artifactType := range []gollumv1alpha1.ArtifactType{gollumv1alpha1.ArtifactsKeyReleaseAssets, gollumv1alpha1.ArtifactsKeyPackagesContainer} {
+		if _, found := data.Spec.PipelineNames[artifactType]; found {
+			artifactTypes++
+		}
+	}
+
+	return cost + len(filteredReleases)*artifactTypes
+}
+
+// getReleaseTimestamps returns the publishing times of all releases that are known for the repository.
+func getReleaseTimestamps(data *gollumv1alpha1.Repository) []time.Time {
+	ret := make([]time.Time, 0, len(data.Status.Releases))
+	for _, release := range data.Status.Releases {
+		if release != nil && release.PublishedAt != nil {
+			ret = append(ret, release.PublishedAt.Time)
+		}
+	}
+	return ret
+}
+
+// getCronRequeueAfter returns the duration until the next activation of the cron expression.
+func getCronRequeueAfter(expr string, now time.Time) (time.Duration, error) {
+	schedule, err := requeue.ParseCron(expr)
+	if err != nil {
+		return 0, err
+	}
+
+	next, err := schedule.Next(now)
+	if err != nil {
+		return 0, err
+	}
+	return next.Sub(now), nil
+}
+
+func getBuildWindow(spec *gollumv1alpha1.BuildWindowSpec) (BuildWindow, error) {
+	location, err := time.LoadLocation(cmp.Or(spec.TimeZone, "UTC"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", spec.TimeZone, err)
+	}
+
+	windows := make([]requeue.Window, 0, len(spec.Windows))
+	for _, windowSpec := range spec.Windows {
+		window, err := requeue.ParseWindow(windowSpec)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+
+	buildWindow, err := requeue.NewCalendarRequeue(location, windows, nil)
+	if err != nil {
+		return nil, err
+	}
+	return buildWindow, nil
+}
+
+// needsFullCheck returns whether releases and artifacts need to be fetched regardless of detected changes.
+func needsFullCheck(data *gollumv1alpha1.Repository, now time.Time) bool {
+	if data.Status.LastFullCheck == nil || now.Sub(data.Status.LastFullCheck.Time) > fullCheckInterval {
+		return true
+	}
+
+	if data.Status.ObservedGeneration != data.Generation {
+		return true
+	}
+
+	// missing artifacts are uploaded by PipelineRuns, which does not necessarily cause a release event
+	for _, release := range data.Status.Releases {
+		if release == nil {
+			continue
+		}
+		for _, isMissing := range release.MissingArtifacts {
+			if isMissing {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func isPipelineRunExpired(creationDate time.Time) bool {
 	// TODO: make configurable
 	expiry := time.Now().Add(-14 * 24 * time.Hour)
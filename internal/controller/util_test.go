@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+	"github.com/soerenschneider/gollum/internal/github"
+)
+
+func TestIsLowPriorityRelease(t *testing.T) {
+	data := &gollumv1alpha1.Repository{}
+	data.Status.Releases = map[string]*gollumv1alpha1.Release{
+		"v1.0.0": {MissingArtifacts: map[gollumv1alpha1.ArtifactType]bool{gollumv1alpha1.ArtifactsKeyReleaseAssets: false}},
+		"v1.1.0": {MissingArtifacts: map[gollumv1alpha1.ArtifactType]bool{gollumv1alpha1.ArtifactsKeyReleaseAssets: true}},
+	}
+
+	tests := []struct {
+		tag  string
+		want bool
+	}{
+		{tag: "v1.0.0", want: true},
+		{tag: "v1.1.0", want: false},
+		{tag: "v2.0.0", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if got := isLowPriorityRelease(data, tt.tag); got != tt.want {
+				t.Errorf("isLowPriorityRelease() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBuildWindow(t *testing.T) {
+	// monday
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		spec     *gollumv1alpha1.BuildWindowSpec
+		wantNext time.Time
+		wantErr  bool
+	}{
+		{
+			name:     "open",
+			spec:     &gollumv1alpha1.BuildWindowSpec{Windows: []string{"mon-fri 08:00-18:00"}},
+			wantNext: now,
+		},
+		{
+			name:     "closed, defaults to UTC",
+			spec:     &gollumv1alpha1.BuildWindowSpec{Windows: []string{"22:00-06:00"}},
+			wantNext: time.Date(2025, 6, 2, 22, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "time zone",
+			spec:     &gollumv1alpha1.BuildWindowSpec{Windows: []string{"sat,sun 00:00-24:00"}, TimeZone: "Europe/Berlin"},
+			wantNext: time.Date(2025, 6, 6, 22, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "invalid time zone",
+			spec:    &gollumv1alpha1.BuildWindowSpec{Windows: []string{"08:00-18:00"}, TimeZone: "Mars/Olympus"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid window",
+			spec:    &gollumv1alpha1.BuildWindowSpec{Windows: []string{"08:00"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buildWindow, err := getBuildWindow(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getBuildWindow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			next, err := buildWindow.NextActive(now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !next.Equal(tt.wantNext) {
+				t.Errorf("NextActive() = %v, want %v", next, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestEstimatePollCost(t *testing.T) {
+	// 150 releases that have been built already
+	known := map[string]*gollumv1alpha1.Release{}
+	var knownReleases []github.Release
+	for i := 0; i < 150; i++ {
+		tag := fmt.Sprintf("v1.%d.0", i)
+		known[tag] = &gollumv1alpha1.Release{MissingArtifacts: map[gollumv1alpha1.ArtifactType]bool{gollumv1alpha1.ArtifactsKeyReleaseAssets: false}}
+		knownReleases = append(knownReleases, github.Release{TagName: tag})
+	}
+	newReleases := []github.Release{{TagName: "v2.0.0"}, {TagName: "v2.1.0"}, {TagName: "v2.2.0"}}
+
+	tests := []struct {
+		name             string
+		memorize         bool
+		pipelines        map[gollumv1alpha1.ArtifactType]string
+		releases         []github.Release
+		filteredReleases []github.Release
+		want             int
+	}{
+		{
+			name: "no releases",
+			want: 1,
+		},
+		{
+			name:      "known releases are listed",
+			pipelines: map[gollumv1alpha1.ArtifactType]string{gollumv1alpha1.ArtifactsKeyReleaseAssets: "build"},
+			releases:  knownReleases,
+			want:      2,
+		},
+		{
+			name:      "memorized releases are not listed, but paginated",
+			memorize:  true,
+			pipelines: map[gollumv1alpha1.ArtifactType]string{gollumv1alpha1.ArtifactsKeyReleaseAssets: "build"},
+			want:      2,
+		},
+		{
+			name: "artifacts of filtered releases",
+			pipelines: map[gollumv1alpha1.ArtifactType]string{
+				gollumv1alpha1.ArtifactsKeyReleaseAssets:     "build",
+				gollumv1alpha1.ArtifactsKeyPackagesContainer: "image",
+			},
+			releases:         append(append([]github.Release{}, knownReleases...), newReleases...),
+			filteredReleases: newReleases,
+			want:             2 + 3*2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &gollumv1alpha1.Repository{}
+			data.Spec.MemorizeReleases = tt.memorize
+			data.Spec.PipelineNames = tt.pipelines
+			data.Status.Releases = known
+
+			if got := estimatePollCost(data, tt.releases, tt.filteredReleases); got != tt.want {
+				t.Errorf("estimatePollCost() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/soerenschneider/gollum/internal/github"
+	"github.com/soerenschneider/gollum/internal/metrics"
+	"golang.org/x/exp/slices"
+)
+
+const pageSize = 50
+
+// GiteaClient talks to the API of a Gitea or Forgejo instance. It returns the same types as the GitHub client, so
+// the controller can treat both providers alike.
+type GiteaClient struct {
+	httpClient *http.Client
+	baseUrl    string
+	token      *string
+
+	// unauthorized is as bool that is true when the system detects we lack permissions to call the packages API.
+	// this is used to prevent wasting further calls to the API.
+	unauthorized atomic.Bool
+}
+
+func NewGiteaClient(client *http.Client, baseUrl string, token *string) (*GiteaClient, error) {
+	parsed, err := url.Parse(baseUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base url %q: %w", baseUrl, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid base url %q: expected http(s)://host", baseUrl)
+	}
+
+	if client == nil {
+		client = &http.Client{
+			Timeout: 5 * time.Second,
+		}
+	}
+
+	return &GiteaClient{
+		httpClient: client,
+		baseUrl:    strings.TrimSuffix(parsed.String(), "/"),
+		token:      token,
+	}, nil
+}
+
+func (g *GiteaClient) GetReleases(ctx context.Context, params github.RepoQuery) ([]github.Release, error) {
+	metrics.GiteaRequestsTotal.WithLabelValues(params.Owner, params.Repo).Inc()
+	endpoint := fmt.Sprintf("%s/api/v1/repos/%s/%s/releases", g.baseUrl, url.PathEscape(params.Owner), url.PathEscape(params.Repo))
+
+	var releases []github.Release
+	err := g.getPaginated(ctx, endpoint, nil, func(body []byte) (int, error) {
+		var parsed []github.Release
+		if err := json.Unmarshal(body, &parsed); err != nil {
+			return 0, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		releases = append(releases, parsed...)
+		return len(parsed), nil
+	})
+	if err != nil {
+		metrics.GiteaRequestErrors.WithLabelValues(params.Owner, params.Repo, "releases").Inc()
+		return nil, err
+	}
+
+	if len(releases) == 0 {
+		return nil, errors.New("no releases found for the repository")
+	}
+
+	var ret []github.Release
+	for _, release := range releases {
+		// ignore releases that are already built
+		if !slices.Contains(params.IgnoreReleases, release.TagName) {
+			ret = append(ret, release)
+		}
+	}
+
+	return ret, nil
+}
+
+func (g *GiteaClient) GetAssets(ctx context.Context, query github.ArtifactQuery) ([]github.ReleaseAsset, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/repos/%s/%s/releases/%d/assets", g.baseUrl, url.PathEscape(query.Owner), url.PathEscape(query.Repo), query.Release.ID)
+
+	var assets []github.ReleaseAsset
+	err := g.getPaginated(ctx, endpoint, nil, func(body []byte) (int, error) {
+		var parsed []github.ReleaseAsset
+		if err := json.Unmarshal(body, &parsed); err != nil {
+			return 0, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		assets = append(assets, parsed...)
+		return len(parsed), nil
+	})
+	if err != nil {
+		metrics.GiteaRequestErrors.WithLabelValues(query.Owner, query.Repo, "assets").Inc()
+		return nil, err
+	}
+
+	return assets, nil
+}
+
+func (g *GiteaClient) GetPackages(ctx context.Context, query github.ArtifactQuery) ([]github.Package, error) {
+	if g.unauthorized.Load() {
+		return nil, github.ErrUnauthorized
+	}
+
+	endpoint := fmt.Sprintf("%s/api/v1/packages/%s", g.baseUrl, url.PathEscape(query.Owner))
+	params := url.Values{}
+	params.Add("type", "container")
+	params.Add("q", query.Repo)
+
+	var ret []github.Package
+	err := g.getPaginated(ctx, endpoint, params, func(body []byte) (int, error) {
+		var parsed []Package
+		if err := json.Unmarshal(body, &parsed); err != nil {
+			return 0, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+
+		for _, p := range parsed {
+			// the search is a substring match, only keep exact matches for the requested tag
+			if p.Name == query.Repo && p.Version == query.Release.TagName {
+				ret = append(ret, convertPackage(p))
+			}
+		}
+		return len(parsed), nil
+	})
+	if err != nil {
+		if errors.Is(err, github.ErrUnauthorized) {
+			g.unauthorized.Store(true)
+		}
+		metrics.GiteaRequestErrors.WithLabelValues(query.Owner, query.Repo, "packages").Inc()
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// ResolveTag returns the SHA of the commit a tag points to.
+func (g *GiteaClient) ResolveTag(ctx context.Context, owner, repo, tag string) (string, error) {
+	metrics.GiteaRequestsTotal.WithLabelValues(owner, repo).Inc()
+	endpoint := fmt.Sprintf("%s/api/v1/repos/%s/%s/tags/%s", g.baseUrl, url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(tag))
+
+	body, _, err := g.get(ctx, endpoint)
+	if err != nil {
+		metrics.GiteaRequestErrors.WithLabelValues(owner, repo, "tags").Inc()
+		return "", fmt.Errorf("could not resolve tag %q: %w", tag, err)
+	}
+
+	var parsed Tag
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return "", fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	if parsed.Commit.SHA == "" {
+		return "", fmt.Errorf("tag %q does not point to a commit", tag)
+	}
+
+	return parsed.Commit.SHA, nil
+}
+
+// getPaginated requests all pages of the given endpoint and hands each page's body to the supplied parse function,
+// which returns the amount of items found on the page.
+func (g *GiteaClient) getPaginated(ctx context.Context, endpoint string, params url.Values, parse func(body []byte) (int, error)) error {
+	parsedURL, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+
+	if params == nil {
+		params = url.Values{}
+	}
+
+	page := 1
+	hasNextPage := true
+	for hasNextPage {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			err := func() error {
+				params.Set("limit", strconv.Itoa(pageSize))
+				params.Set("page", strconv.Itoa(page))
+				parsedURL.RawQuery = params.Encode()
+
+				body, header, err := g.get(ctx, parsedURL.String())
+				if err != nil {
+					return err
+				}
+
+				items, err := parse(body)
+				if err != nil {
+					return err
+				}
+
+				// not all versions of Gitea send a link header, stop at the first page that is not full
+				linkHeader := header.Get("Link")
+				if linkHeader != "" {
+					hasNextPage = strings.Contains(linkHeader, "rel=\"next\"")
+				} else {
+					hasNextPage = items >= pageSize
+				}
+				page++
+
+				return nil
+			}()
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// get requests the given endpoint and returns the body and headers of the response.
+func (g *GiteaClient) get(ctx context.Context, endpoint string) ([]byte, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if g.token != nil && *g.token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("token %s", *g.token))
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, evaluateAndTransformError(resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return body, resp.Header, nil
+}
+
+func evaluateAndTransformError(resp *http.Response) error {
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return github.ErrUnauthorized
+	}
+
+	return fmt.Errorf("got status code %d", resp.StatusCode)
+}
+
+func convertPackage(p Package) github.Package {
+	ret := github.Package{
+		Name:      p.Name,
+		Tag:       p.Version,
+		CreatedAt: p.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: p.CreatedAt.Format(time.RFC3339),
+	}
+
+	if p.Repository != nil {
+		ret.Repository = p.Repository.FullName
+	}
+
+	return ret
+}
@@ -0,0 +1,133 @@
+package gitea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/soerenschneider/gollum/internal/github"
+)
+
+func TestGiteaClient_GetReleases(t *testing.T) {
+	token := "secret"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/owner/repo/releases" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "token secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Query().Get("page") {
+		case "1":
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=2>; rel="next"`, "http://"+r.Host, r.URL.Path))
+			_, _ = w.Write([]byte(`[{"id": 1, "tag_name": "v1.0.0"}, {"id": 2, "tag_name": "v1.1.0"}]`))
+		case "2":
+			_, _ = w.Write([]byte(`[{"id": 3, "tag_name": "v1.2.0"}]`))
+		default:
+			_, _ = w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewGiteaClient(server.Client(), server.URL+"/", &token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := client.GetReleases(context.Background(), github.RepoQuery{
+		Owner:          "owner",
+		Repo:           "repo",
+		IgnoreReleases: []string{"v1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("GetReleases() error = %v", err)
+	}
+
+	if len(got) != 2 || got[0].TagName != "v1.1.0" || got[1].TagName != "v1.2.0" {
+		t.Errorf("GetReleases() got = %v", got)
+	}
+}
+
+func TestGiteaClient_GetPackages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/packages/owner" || r.URL.Query().Get("type") != "container" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`[
+			{"id": 1, "type": "container", "name": "repo", "version": "v1.0.0"},
+			{"id": 2, "type": "container", "name": "repo", "version": "v1.1.0"},
+			{"id": 3, "type": "container", "name": "repo-other", "version": "v1.0.0"}
+		]`))
+	}))
+	defer server.Close()
+
+	client, err := NewGiteaClient(server.Client(), server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := client.GetPackages(context.Background(), github.ArtifactQuery{
+		Owner:   "owner",
+		Repo:    "repo",
+		Release: github.Release{TagName: "v1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("GetPackages() error = %v", err)
+	}
+
+	if len(got) != 1 || got[0].Name != "repo" || got[0].Tag != "v1.0.0" {
+		t.Errorf("GetPackages() got = %v", got)
+	}
+}
+
+func TestGiteaClient_Unauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client, err := NewGiteaClient(server.Client(), server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.GetReleases(context.Background(), github.RepoQuery{Owner: "owner", Repo: "repo"})
+	if !errors.Is(err, github.ErrUnauthorized) {
+		t.Errorf("GetReleases() error = %v, want %v", err, github.ErrUnauthorized)
+	}
+}
+
+func TestGiteaClient_ResolveTag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/owner/repo/tags/v1.0.0" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"name": "v1.0.0", "id": "5f2c", "commit": {"sha": "0a1b2c3d"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewGiteaClient(server.Client(), server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := client.ResolveTag(context.Background(), "owner", "repo", "v1.0.0")
+	if err != nil {
+		t.Fatalf("ResolveTag() error = %v", err)
+	}
+	if got != "0a1b2c3d" {
+		t.Errorf("ResolveTag() got = %q, want %q", got, "0a1b2c3d")
+	}
+
+	if _, err := client.ResolveTag(context.Background(), "owner", "repo", "v2.0.0"); err == nil {
+		t.Error("ResolveTag() expected error for unknown tag")
+	}
+}
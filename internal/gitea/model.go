@@ -0,0 +1,27 @@
+package gitea
+
+import (
+	"time"
+)
+
+// Package is a single package version as returned by the Gitea/Forgejo packages API.
+type Package struct {
+	ID        int64     `json:"id"`
+	Type      string    `json:"type"`
+	Name      string    `json:"name"`
+	Version   string    `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	HtmlURL   string    `json:"html_url"`
+
+	Repository *struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// Tag is a tag as returned by the Gitea/Forgejo tags API.
+type Tag struct {
+	Name   string `json:"name"`
+	Commit struct {
+		SHA string `json:"sha"`
+	} `json:"commit"`
+}
@@ -0,0 +1,266 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultApiBaseUrl = "https://api.github.com"
+
+	// jwtLifetime is the lifetime of the JWTs used to authenticate as the app, GitHub allows at most 10 minutes.
+	jwtLifetime = 9 * time.Minute
+	// jwtClockDrift is subtracted from the issuing time to protect against clock drift.
+	jwtClockDrift = 60 * time.Second
+	// tokenRefreshMargin defines how long before their expiry installation tokens are refreshed.
+	tokenRefreshMargin = 5 * time.Minute
+)
+
+var ErrNoInstallation = errors.New("app is not installed for owner")
+
+type installationToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type installation struct {
+	ID int64 `json:"id"`
+}
+
+// AppTokenSource authenticates as a GitHub App. It exchanges JWTs signed with the app's private key for installation
+// tokens, one per owner the app is installed for, and refreshes them before they expire.
+type AppTokenSource struct {
+	httpClient *http.Client
+	baseUrl    string
+	appId      int64
+	privateKey *rsa.PrivateKey
+
+	// mutex guards the installations map only, it is never held during requests
+	mutex         sync.Mutex
+	installations map[string]*appInstallation
+}
+
+// appInstallation is the installation of the app for a single owner. Its mutex serializes the token exchange, so
+// concurrent requests for the same owner wait for a single exchange while other owners are not blocked.
+type appInstallation struct {
+	mutex sync.Mutex
+	id    int64
+	token installationToken
+}
+
+func NewAppTokenSource(client *http.Client, appId int64, privateKeyPem []byte) (*AppTokenSource, error) {
+	if appId <= 0 {
+		return nil, errors.New("app id must be > 0")
+	}
+
+	privateKey, err := parsePrivateKey(privateKeyPem)
+	if err != nil {
+		return nil, err
+	}
+
+	if client == nil {
+		client = &http.Client{
+			Timeout: 5 * time.Second,
+		}
+	}
+
+	return &AppTokenSource{
+		httpClient:    client,
+		baseUrl:       defaultApiBaseUrl,
+		appId:         appId,
+		privateKey:    privateKey,
+		installations: map[string]*appInstallation{},
+	}, nil
+}
+
+func (a *AppTokenSource) credentialName() string {
+	return fmt.Sprintf("app-%d", a.appId)
+}
+
+// hasPerOwnerRateLimits returns true, as each installation token has its own rate limit.
+func (a *AppTokenSource) hasPerOwnerRateLimits() bool {
+	return true
+}
+
+func (a *AppTokenSource) getInstallation(owner string) *appInstallation {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	ret, found := a.installations[owner]
+	if !found {
+		ret = &appInstallation{}
+		a.installations[owner] = ret
+	}
+	return ret
+}
+
+// Token returns a valid installation token for the installation of the app for the given owner.
+func (a *AppTokenSource) Token(ctx context.Context, owner string) (string, error) {
+	installation := a.getInstallation(owner)
+	installation.mutex.Lock()
+	defer installation.mutex.Unlock()
+
+	if installation.token.Token != "" && time.Until(installation.token.ExpiresAt) > tokenRefreshMargin {
+		return installation.token.Token, nil
+	}
+
+	if installation.id == 0 {
+		id, err := a.getInstallationId(ctx, owner)
+		if err != nil {
+			return "", err
+		}
+		installation.id = id
+	}
+
+	token, err := a.createInstallationToken(ctx, installation.id)
+	if err != nil {
+		// the installation may have been removed and installed again, look it up again on the next try
+		installation.id = 0
+		return "", err
+	}
+	installation.token = token
+
+	return token.Token, nil
+}
+
+func (a *AppTokenSource) getInstallationId(ctx context.Context, owner string) (int64, error) {
+	for _, kind := range []string{"orgs", "users"} {
+		endpoint := fmt.Sprintf("%s/%s/%s/installation", a.baseUrl, kind, url.PathEscape(owner))
+		var parsed installation
+		status, err := a.doAsApp(ctx, http.MethodGet, endpoint, &parsed)
+		if err != nil {
+			return 0, err
+		}
+
+		if status == http.StatusOK {
+			return parsed.ID, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w %q", ErrNoInstallation, owner)
+}
+
+func (a *AppTokenSource) createInstallationToken(ctx context.Context, installationId int64) (installationToken, error) {
+	endpoint := fmt.Sprintf("%s/app/installations/%d/access_tokens", a.baseUrl, installationId)
+	var parsed installationToken
+	status, err := a.doAsApp(ctx, http.MethodPost, endpoint, &parsed)
+	if err != nil {
+		return installationToken{}, err
+	}
+
+	if status != http.StatusCreated {
+		return installationToken{}, fmt.Errorf("could not create installation token, got status code %d", status)
+	}
+
+	return parsed, nil
+}
+
+// doAsApp sends a request authenticated as the app itself and parses the body into target, if the request was
+// successful. Unsuccessful status codes other than 404 are returned as errors.
+func (a *AppTokenSource) doAsApp(ctx context.Context, method, endpoint string, target any) (int, error) {
+	jwt, err := a.signJwt(time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("could not sign jwt: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", jwt))
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read response body: %w", err)
+		}
+		if err := json.Unmarshal(body, target); err != nil {
+			return 0, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		return resp.StatusCode, nil
+	case http.StatusNotFound:
+		return resp.StatusCode, nil
+	case http.StatusUnauthorized:
+		return resp.StatusCode, ErrUnauthorized
+	default:
+		return resp.StatusCode, fmt.Errorf("got status code %d", resp.StatusCode)
+	}
+}
+
+// signJwt builds a JWT that is signed using RS256 with the app's private key.
+func (a *AppTokenSource) signJwt(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+	})
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-jwtClockDrift).Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+		"iss": strconv.FormatInt(a.appId, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hashed := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.privateKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		return "", err
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM encoded private key found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse private key: %w", err)
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not a RSA key")
+	}
+
+	return rsaKey, nil
+}
@@ -0,0 +1,129 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func generatePrivateKey(t *testing.T) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+func TestAppTokenSource_Token(t *testing.T) {
+	var tokensCreated atomic.Int32
+	expiresIn := time.Hour
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") || strings.Count(r.Header.Get("Authorization"), ".") != 2 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/orgs/soerenschneider/installation":
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodGet && r.URL.Path == "/users/soerenschneider/installation":
+			_, _ = w.Write([]byte(`{"id": 42}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/app/installations/42/access_tokens":
+			count := tokensCreated.Add(1)
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(installationToken{
+				Token:     fmt.Sprintf("token-%d", count),
+				ExpiresAt: time.Now().Add(expiresIn),
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	source, err := NewAppTokenSource(server.Client(), 1, generatePrivateKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	source.baseUrl = server.URL
+
+	token, err := source.Token(context.Background(), "soerenschneider")
+	if err != nil || token != "token-1" {
+		t.Fatalf("Token() = %q, %v, want token-1", token, err)
+	}
+
+	// token is still valid, expect it to be cached
+	token, _ = source.Token(context.Background(), "soerenschneider")
+	if token != "token-1" {
+		t.Errorf("Token() = %q, want cached token-1", token)
+	}
+
+	// token is about to expire, expect it to be refreshed
+	source.installations["soerenschneider"].token = installationToken{Token: "token-1", ExpiresAt: time.Now().Add(time.Minute)}
+	token, _ = source.Token(context.Background(), "soerenschneider")
+	if token != "token-2" {
+		t.Errorf("Token() = %q, want refreshed token-2", token)
+	}
+
+	if _, err := source.Token(context.Background(), "unknown"); err == nil {
+		t.Errorf("expected error for owner without installation")
+	}
+}
+
+func TestAppTokenSource_TokenConcurrentOwners(t *testing.T) {
+	release := make(chan struct{})
+	pending := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/orgs/slow/installation":
+			close(pending)
+			<-release
+			_, _ = w.Write([]byte(`{"id": 1}`))
+		case "/orgs/fast/installation":
+			_, _ = w.Write([]byte(`{"id": 2}`))
+		default:
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(installationToken{Token: r.URL.Path, ExpiresAt: time.Now().Add(time.Hour)})
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	source, err := NewAppTokenSource(server.Client(), 1, generatePrivateKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	source.baseUrl = server.URL
+
+	go func() {
+		_, _ = source.Token(context.Background(), "slow")
+	}()
+	<-pending
+
+	// the pending token exchange of another owner must not block
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := source.Token(context.Background(), "fast"); err != nil {
+			t.Errorf("Token() error = %v", err)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Token() blocked by token exchange of another owner")
+	}
+}
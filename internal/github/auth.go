@@ -0,0 +1,106 @@
+package github
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"golang.org/x/exp/slices"
+)
+
+// TokenSource supplies the token that is used to authenticate requests against resources of the given owner.
+type TokenSource interface {
+	Token(ctx context.Context, owner string) (string, error)
+}
+
+// StaticTokenSource returns the same token, regardless of the owner. This is used for personal access tokens. The token
+// can be swapped atomically at runtime.
+type StaticTokenSource struct {
+	token atomic.Pointer[string]
+	// name identifies the source in metrics. It is derived from the initial token, so it is stable across clients
+	// and token swaps.
+	name string
+}
+
+func NewStaticTokenSource(token string) *StaticTokenSource {
+	hash := sha256.Sum256([]byte(token))
+	ret := &StaticTokenSource{
+		name: "token-" + hex.EncodeToString(hash[:])[:8],
+	}
+	ret.SetToken(token)
+	return ret
+}
+
+func (s *StaticTokenSource) credentialName() string {
+	return s.name
+}
+
+func (s *StaticTokenSource) Token(_ context.Context, _ string) (string, error) {
+	return *s.token.Load(), nil
+}
+
+func (s *StaticTokenSource) SetToken(token string) {
+	s.token.Store(&token)
+}
+
+// SetToken atomically replaces the token of a client that authenticates using a static token. As the new token may
+// carry different permissions, the latch that prevents calls to the packages API is reset. The rate-limit state is
+// kept.
+func (g *GithubClient) SetToken(token string) error {
+	if len(g.credentials) != 1 {
+		return errors.New("client does not authenticate using a single token")
+	}
+
+	source, ok := g.credentials[0].source.(*StaticTokenSource)
+	if !ok {
+		return errors.New("client does not authenticate using a static token")
+	}
+
+	source.SetToken(token)
+	g.unauthorized.Store(false)
+	return nil
+}
+
+// GetTokenInfo returns the identity and the scopes of the token that is used for requests on behalf of the given
+// owner.
+func (g *GithubClient) GetTokenInfo(ctx context.Context, owner string) (*TokenInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com/user", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := g.do(ctx, req, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	ret := &TokenInfo{}
+	if err := json.Unmarshal(body, ret); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	for _, scope := range strings.Split(resp.Header.Get("X-OAuth-Scopes"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			ret.Scopes = append(ret.Scopes, scope)
+		}
+	}
+	slices.Sort(ret.Scopes)
+
+	return ret, nil
+}
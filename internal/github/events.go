@@ -0,0 +1,179 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/soerenschneider/gollum/internal/metrics"
+)
+
+const eventsPerPage = 100
+
+// eventsState keeps track of the events that have been seen for a single repository.
+type eventsState struct {
+	etag        string
+	lastEventId int64
+	// nextPoll is the earliest time GitHub wants the events to be polled again, according to the X-Poll-Interval
+	// header.
+	nextPoll time.Time
+	// pending is set once release related events have been seen and cleared by ConfirmReleaseEvents, so events are
+	// not lost if fetching the releases fails.
+	pending bool
+}
+
+type repoEvent struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Payload struct {
+		RefType string `json:"ref_type"`
+	} `json:"payload"`
+}
+
+// isReleaseEvent returns whether the event may indicate a new release or new artifacts.
+func (e repoEvent) isReleaseEvent() bool {
+	switch e.Type {
+	case "ReleaseEvent", "RegistryPackageEvent", "PackageEvent":
+		return true
+	case "CreateEvent":
+		return e.Payload.RefType == "tag"
+	}
+	return false
+}
+
+// HasReleaseEvents polls the events of the repository and returns whether a release related event happened since the
+// last call. Conditional requests are used, so polling an idle repository does not count against the rate limit. If
+// the state of the repository is unknown, e.g. on the first call, true is returned. Detected events are reported until
+// they are confirmed using ConfirmReleaseEvents.
+func (g *GithubClient) HasReleaseEvents(ctx context.Context, owner, repo string) (bool, error) {
+	key := fmt.Sprintf("%s/%s", owner, repo)
+
+	g.eventsMutex.Lock()
+	state, found := g.events[key]
+	pending := found && state.pending
+	g.eventsMutex.Unlock()
+
+	if pending {
+		return true, nil
+	}
+
+	if found && time.Now().Before(state.nextPoll) {
+		return false, nil
+	}
+
+	endpoint := fmt.Sprintf("https://api.github.com/repos/%s/%s/events?per_page=%d", owner, repo, eventsPerPage)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	if found && state.etag != "" {
+		req.Header.Set("If-None-Match", state.etag)
+	}
+
+	metrics.GithubRequestsTotal.WithLabelValues(owner, repo).Inc()
+	resp, err := g.do(ctx, req, owner)
+	if err != nil {
+		metrics.GithubRequestErrors.WithLabelValues(owner, repo, "events").Inc()
+		return false, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	newState := &eventsState{
+		etag:     resp.Header.Get("ETag"),
+		nextPoll: time.Now().Add(getPollInterval(resp)),
+	}
+	if found {
+		newState.lastEventId = state.lastEventId
+		if newState.etag == "" {
+			newState.etag = state.etag
+		}
+	}
+
+	changed := !found
+	if resp.StatusCode != http.StatusNotModified {
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			metrics.GithubRequestErrors.WithLabelValues(owner, repo, "events").Inc()
+			return false, err
+		}
+
+		var events []repoEvent
+		if err := json.Unmarshal(data, &events); err != nil {
+			metrics.GithubRequestErrors.WithLabelValues(owner, repo, "events").Inc()
+			return false, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+
+		var hasReleaseEvents bool
+		newState.lastEventId, hasReleaseEvents = evaluateEvents(events, newState.lastEventId)
+		changed = changed || hasReleaseEvents
+	}
+	newState.pending = changed
+
+	g.eventsMutex.Lock()
+	g.events[key] = newState
+	g.eventsMutex.Unlock()
+
+	return changed, nil
+}
+
+// ConfirmReleaseEvents marks the release related events of the repository as processed. It is called after the
+// releases of the repository have been fetched successfully.
+func (g *GithubClient) ConfirmReleaseEvents(owner, repo string) {
+	key := fmt.Sprintf("%s/%s", owner, repo)
+
+	g.eventsMutex.Lock()
+	defer g.eventsMutex.Unlock()
+	if state, found := g.events[key]; found {
+		state.pending = false
+	}
+}
+
+// evaluateEvents returns the id of the most recent event and whether a release related event happened after the
+// event with the given id. If all events on the page are newer than the given id, events may have been missed and
+// true is returned.
+func evaluateEvents(events []repoEvent, lastEventId int64) (int64, bool) {
+	mostRecentId := lastEventId
+	oldestId := int64(-1)
+	hasReleaseEvents := false
+
+	for _, event := range events {
+		id, err := strconv.ParseInt(event.ID, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		mostRecentId = max(mostRecentId, id)
+		if oldestId < 0 || id < oldestId {
+			oldestId = id
+		}
+		if id > lastEventId && event.isReleaseEvent() {
+			hasReleaseEvents = true
+		}
+	}
+
+	missedEvents := lastEventId > 0 && len(events) >= eventsPerPage && oldestId > lastEventId
+	return mostRecentId, hasReleaseEvents || missedEvents
+}
+
+func getPollInterval(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("X-Poll-Interval"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Forget drops the events state of the repository, e.g. after it is not watched anymore.
+func (g *GithubClient) Forget(owner, repo string) {
+	key := fmt.Sprintf("%s/%s", owner, repo)
+
+	g.eventsMutex.Lock()
+	defer g.eventsMutex.Unlock()
+	delete(g.events, key)
+}
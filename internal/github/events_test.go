@@ -0,0 +1,145 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEvaluateEvents(t *testing.T) {
+	push := func(id string) repoEvent {
+		return repoEvent{ID: id, Type: "PushEvent"}
+	}
+	release := func(id string) repoEvent {
+		return repoEvent{ID: id, Type: "ReleaseEvent"}
+	}
+	createTag := func(id string) repoEvent {
+		e := repoEvent{ID: id, Type: "CreateEvent"}
+		e.Payload.RefType = "tag"
+		return e
+	}
+	createBranch := func(id string) repoEvent {
+		e := repoEvent{ID: id, Type: "CreateEvent"}
+		e.Payload.RefType = "branch"
+		return e
+	}
+
+	tests := []struct {
+		name        string
+		events      []repoEvent
+		lastEventId int64
+		wantId      int64
+		wantChanged bool
+	}{
+		{
+			name:        "no events",
+			lastEventId: 10,
+			wantId:      10,
+		},
+		{
+			name:        "only push events",
+			events:      []repoEvent{push("12"), push("11")},
+			lastEventId: 10,
+			wantId:      12,
+		},
+		{
+			name:        "new release event",
+			events:      []repoEvent{push("12"), release("11"), push("9")},
+			lastEventId: 10,
+			wantId:      12,
+			wantChanged: true,
+		},
+		{
+			name:        "release event has been seen before",
+			events:      []repoEvent{push("12"), release("9")},
+			lastEventId: 10,
+			wantId:      12,
+		},
+		{
+			name:        "new tag",
+			events:      []repoEvent{createTag("11")},
+			lastEventId: 10,
+			wantId:      11,
+			wantChanged: true,
+		},
+		{
+			name:        "new branch",
+			events:      []repoEvent{createBranch("11")},
+			lastEventId: 10,
+			wantId:      11,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotId, gotChanged := evaluateEvents(tt.events, tt.lastEventId)
+			if gotId != tt.wantId {
+				t.Errorf("evaluateEvents() id = %v, want %v", gotId, tt.wantId)
+			}
+			if gotChanged != tt.wantChanged {
+				t.Errorf("evaluateEvents() changed = %v, want %v", gotChanged, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestEvaluateEvents_MissedEvents(t *testing.T) {
+	events := make([]repoEvent, 0, eventsPerPage)
+	for i := 0; i < eventsPerPage; i++ {
+		events = append(events, repoEvent{ID: "100", Type: "PushEvent"})
+	}
+
+	if _, changed := evaluateEvents(events, 10); !changed {
+		t.Errorf("expected a change if events may have been missed")
+	}
+}
+
+func TestGithubClient_HasReleaseEvents(t *testing.T) {
+	var body string
+	client, err := NewGithubClient(&http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		recorder := httptest.NewRecorder()
+		if body == "" {
+			recorder.WriteHeader(http.StatusNotModified)
+		} else {
+			recorder.Header().Set("ETag", body)
+			_, _ = recorder.WriteString(body)
+		}
+		return recorder.Result(), nil
+	})}, NewStaticTokenSource("token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(want bool) {
+		t.Helper()
+		got, err := client.HasReleaseEvents(context.Background(), "owner", "repo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("HasReleaseEvents() = %v, want %v", got, want)
+		}
+	}
+
+	// unknown state
+	body = `[{"id": "10", "type": "PushEvent"}]`
+	check(true)
+	client.ConfirmReleaseEvents("owner", "repo")
+
+	body = ""
+	check(false)
+
+	body = `[{"id": "11", "type": "ReleaseEvent"}, {"id": "10", "type": "PushEvent"}]`
+	check(true)
+
+	// the releases have not been fetched yet, the events are reported again
+	body = ""
+	check(true)
+
+	client.ConfirmReleaseEvents("owner", "repo")
+	check(false)
+
+	// the state of a forgotten repository is unknown
+	client.Forget("owner", "repo")
+	check(true)
+}
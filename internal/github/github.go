@@ -18,21 +18,35 @@ import (
 	"golang.org/x/exp/slices"
 )
 
-var ErrUnauthorized = errors.New("unauthorized. either token is invalid, expired or missing the correct scope")
+var (
+	ErrUnauthorized = errors.New("unauthorized. either token is invalid, expired or missing the correct scope")
+	ErrNotFound     = errors.New("not found")
+)
 
 type GithubClient struct {
 	httpClient *http.Client
-	token      *string
+
+	// credentials is the pool of tokens that is used to spread the requests. For each request, the token with the
+	// most remaining requests is chosen.
+	credentials []*credential
 
 	// unauthorized is as bool that is true when the system detects we lack permissions to call the packages API.
 	// this is used to prevent wasting further calls to the API in order to save quota.
 	unauthorized atomic.Bool
 
-	rateLimitMutex   sync.RWMutex
-	rateLimitedUntil *RateLimitError
+	secondaryRateLimitMutex   sync.RWMutex
+	secondaryRateLimitedUntil *SecondaryRateLimitError
+
+	// rateLimitReserve is the amount of requests per token that is reserved for urgent requests.
+	rateLimitReserve atomic.Int64
+
+	eventsMutex sync.Mutex
+	events      map[string]*eventsState
 }
 
-func NewGithubClient(client *http.Client, token *string) (*GithubClient, error) {
+// NewGithubClient returns a client that authenticates using the given token sources. If no token source is supplied,
+// requests are sent unauthenticated.
+func NewGithubClient(client *http.Client, tokenSources ...TokenSource) (*GithubClient, error) {
 	if client == nil {
 		client = &http.Client{
 			Timeout: 5 * time.Second,
@@ -40,22 +54,14 @@ func NewGithubClient(client *http.Client, token *string) (*GithubClient, error)
 	}
 
 	ret := &GithubClient{
-		httpClient: client,
-		token:      token,
+		httpClient:  client,
+		credentials: newCredentials(tokenSources),
+		events:      map[string]*eventsState{},
 	}
 
 	return ret, nil
 }
 
-func (g *GithubClient) isRateLimited() error {
-	g.rateLimitMutex.RLock()
-	defer g.rateLimitMutex.RUnlock()
-	if g.rateLimitedUntil != nil && time.Now().Before(g.rateLimitedUntil.Info.Reset) {
-		return g.rateLimitedUntil
-	}
-	return nil
-}
-
 func (g *GithubClient) getReleases(ctx context.Context, queryParams RepoQuery) ([]Release, error) {
 	metrics.GithubRequestsTotal.WithLabelValues(queryParams.Owner, queryParams.Repo).Inc()
 	endpoint := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases", queryParams.Owner, queryParams.Repo)
@@ -74,10 +80,6 @@ func (g *GithubClient) getReleases(ctx context.Context, queryParams RepoQuery) (
 			return nil, ctx.Err()
 		default:
 			err := func() error {
-				if err := g.isRateLimited(); err != nil {
-					return err
-				}
-
 				params := url.Values{}
 				params.Add("per_page", "100")
 				params.Add("page", strconv.Itoa(page))
@@ -88,23 +90,15 @@ func (g *GithubClient) getReleases(ctx context.Context, queryParams RepoQuery) (
 					return fmt.Errorf("failed to create request: %w", err)
 				}
 
-				if g.token != nil && *g.token != "" {
-					req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", *g.token))
-				}
-
-				resp, err := g.httpClient.Do(req)
+				resp, err := g.do(ctx, req, queryParams.Owner)
 				if err != nil {
-					return fmt.Errorf("failed to send request: %w", err)
+					return err
 				}
 
 				defer func() {
 					_ = resp.Body.Close()
 				}()
 
-				if resp.StatusCode != http.StatusOK {
-					return g.evaluateAndTransformError(resp)
-				}
-
 				body, err := io.ReadAll(resp.Body)
 				if err != nil {
 					return fmt.Errorf("failed to read response body: %w", err)
@@ -183,21 +177,24 @@ func (g *GithubClient) GetReleases(ctx context.Context, params RepoQuery) ([]Rel
 	return ret, nil
 }
 
-func (g *GithubClient) evaluateAndTransformError(resp *http.Response) error {
+func (g *GithubClient) evaluateAndTransformError(resp *http.Response, cred *credential, owner string) error {
 	if resp.StatusCode == http.StatusOK {
 		return nil
 	}
 
 	genericErr := fmt.Errorf("got status code %d", resp.StatusCode)
 
-	if resp.StatusCode == http.StatusForbidden {
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
 		rateLimitErr := GetRateLimitInfo(resp)
 		if rateLimitErr != nil && rateLimitErr.Info.Remaining <= 0 {
-			g.rateLimitMutex.Lock()
-			defer g.rateLimitMutex.Unlock()
-			g.rateLimitedUntil = rateLimitErr
+			cred.setRateLimited(owner, rateLimitErr)
 			return rateLimitErr
 		}
+
+		if secondaryRateLimitErr := getSecondaryRateLimitInfo(resp); secondaryRateLimitErr != nil {
+			g.setSecondaryRateLimited(secondaryRateLimitErr)
+			return secondaryRateLimitErr
+		}
 		return genericErr
 	}
 
@@ -205,14 +202,14 @@ func (g *GithubClient) evaluateAndTransformError(resp *http.Response) error {
 		return ErrUnauthorized
 	}
 
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %w", ErrNotFound, genericErr)
+	}
+
 	return genericErr
 }
 
 func (g *GithubClient) GetAssets(ctx context.Context, query ArtifactQuery) ([]ReleaseAsset, error) {
-	if err := g.isRateLimited(); err != nil {
-		return nil, err
-	}
-
 	endpoint := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/%d/assets", query.Owner, query.Repo, query.Release.ID)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
@@ -220,11 +217,7 @@ func (g *GithubClient) GetAssets(ctx context.Context, query ArtifactQuery) ([]Re
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	if g.token != nil && *g.token != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", *g.token))
-	}
-
-	resp, err := g.httpClient.Do(req)
+	resp, err := g.do(ctx, req, query.Owner)
 	if err != nil {
 		metrics.GithubRequestErrors.WithLabelValues(query.Owner, query.Repo, "assets").Inc()
 		return nil, err
@@ -234,10 +227,6 @@ func (g *GithubClient) GetAssets(ctx context.Context, query ArtifactQuery) ([]Re
 		_ = resp.Body.Close()
 	}()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, g.evaluateAndTransformError(resp)
-	}
-
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
 		metrics.GithubRequestErrors.WithLabelValues(query.Owner, query.Repo, "assets").Inc()
@@ -292,10 +281,6 @@ func (g *GithubClient) getPackages(ctx context.Context, owner, repo, packageType
 			return nil, ctx.Err()
 		default:
 			err := func() error {
-				if err := g.isRateLimited(); err != nil {
-					return err
-				}
-
 				params := url.Values{}
 				params.Add("per_page", "100")
 				params.Add("page", strconv.Itoa(page))
@@ -306,11 +291,7 @@ func (g *GithubClient) getPackages(ctx context.Context, owner, repo, packageType
 					return err
 				}
 
-				if g.token != nil && *g.token != "" {
-					req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", *g.token))
-				}
-
-				resp, err := g.httpClient.Do(req)
+				resp, err := g.do(ctx, req, owner)
 				if err != nil {
 					metrics.GithubRequestErrors.WithLabelValues(owner, repo, "packages").Inc()
 					return err
@@ -320,10 +301,6 @@ func (g *GithubClient) getPackages(ctx context.Context, owner, repo, packageType
 					_ = resp.Body.Close()
 				}()
 
-				if resp.StatusCode != http.StatusOK {
-					return g.evaluateAndTransformError(resp)
-				}
-
 				data, err := io.ReadAll(resp.Body)
 				if err != nil {
 					metrics.GithubRequestErrors.WithLabelValues(owner, repo, "packages").Inc()
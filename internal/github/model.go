@@ -1,8 +1,11 @@
 package github
 
 import (
+	"errors"
 	"fmt"
 	"time"
+
+	"golang.org/x/exp/slices"
 )
 
 type ArtifactQuery struct {
@@ -22,7 +25,29 @@ type Release struct {
 	ID      int64  `json:"id"`
 	TagName string `json:"tag_name"`
 
-	HasAssets *bool `json:"has_assets"`
+	HasAssets   *bool      `json:"has_assets"`
+	PublishedAt *time.Time `json:"published_at"`
+	Prerelease  bool       `json:"prerelease"`
+	UploadURL   string     `json:"upload_url"`
+}
+
+// gitRef is a git reference or an annotated tag as returned by the git database API.
+type gitRef struct {
+	Object struct {
+		Type string `json:"type"`
+		SHA  string `json:"sha"`
+	} `json:"object"`
+}
+
+type Repository struct {
+	Name  string `json:"name"`
+	Owner struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+	Topics   []string `json:"topics"`
+	Language string   `json:"language"`
+	Archived bool     `json:"archived"`
+	Fork     bool     `json:"fork"`
 }
 
 type ReleaseAsset struct {
@@ -49,6 +74,19 @@ type Package struct {
 	Repository string `json:"repository"`
 }
 
+type TokenInfo struct {
+	Login  string `json:"login"`
+	Scopes []string
+}
+
+// Equals returns true if both tokens belong to the same identity and carry the same scopes.
+func (t *TokenInfo) Equals(other *TokenInfo) bool {
+	if t == nil || other == nil {
+		return t == other
+	}
+	return t.Login == other.Login && slices.Equal(t.Scopes, other.Scopes)
+}
+
 type RateLimitInfo struct {
 	Limit     int
 	Remaining int
@@ -64,3 +102,35 @@ func (e *RateLimitError) Error() string {
 	return fmt.Sprintf("Rate limit exceeded. Limit: %d, Remaining: %d, Reset at: %s",
 		e.Info.Limit, e.Info.Remaining, e.Info.Reset)
 }
+
+// SecondaryRateLimitError is returned when GitHub's secondary rate limits, that protect against abusive usage, have
+// been hit. No requests must be sent before RetryAfter.
+type SecondaryRateLimitError struct {
+	RetryAfter time.Time
+}
+
+// Error implements the error interface for SecondaryRateLimitError.
+func (e *SecondaryRateLimitError) Error() string {
+	return fmt.Sprintf("Secondary rate limit exceeded. Retry after: %s", e.RetryAfter)
+}
+
+// RetryAt returns the point in time after which requests are allowed again, if err has been caused by either the
+// primary or the secondary rate limit, or if the request has been deferred to preserve the rate limit reserve.
+func RetryAt(err error) (time.Time, bool) {
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return rateLimitErr.Info.Reset, true
+	}
+
+	var secondaryRateLimitErr *SecondaryRateLimitError
+	if errors.As(err, &secondaryRateLimitErr) {
+		return secondaryRateLimitErr.RetryAfter, true
+	}
+
+	var throttledErr *ThrottledError
+	if errors.As(err, &throttledErr) {
+		return throttledErr.Until, true
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,249 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/soerenschneider/gollum/internal/metrics"
+)
+
+// credential is a single token source of the client's token pool. Each credential keeps track of its own rate-limit
+// state. Token sources whose tokens are rate-limited per owner, such as GitHub Apps, keep one state per owner.
+type credential struct {
+	name   string
+	source TokenSource
+
+	mutex  sync.RWMutex
+	states map[string]*rateLimitState
+}
+
+type rateLimitState struct {
+	lastRateLimit    *RateLimitInfo
+	rateLimitedUntil *RateLimitError
+}
+
+// namedTokenSource is implemented by token sources that have a name that is stable across clients. It is used as the
+// metric label of the credential.
+type namedTokenSource interface {
+	credentialName() string
+}
+
+// perOwnerRateLimits is implemented by token sources that use a separate token, and thus rate limit, for each owner.
+type perOwnerRateLimits interface {
+	hasPerOwnerRateLimits() bool
+}
+
+func newCredentials(tokenSources []TokenSource) []*credential {
+	ret := make([]*credential, 0, len(tokenSources))
+	for _, source := range tokenSources {
+		if source != nil {
+			name := strconv.Itoa(len(ret))
+			if named, ok := source.(namedTokenSource); ok {
+				name = named.credentialName()
+			}
+
+			ret = append(ret, &credential{
+				name:   name,
+				source: source,
+				states: map[string]*rateLimitState{},
+			})
+		}
+	}
+
+	// unauthenticated requests have their own (tiny) quota that needs to be tracked as well
+	if len(ret) == 0 {
+		ret = append(ret, &credential{name: "anonymous", states: map[string]*rateLimitState{}})
+	}
+
+	return ret
+}
+
+// stateKey returns the key of the rate-limit state that applies to requests for the owner.
+func (c *credential) stateKey(owner string) string {
+	if source, ok := c.source.(perOwnerRateLimits); ok && source.hasPerOwnerRateLimits() {
+		return owner
+	}
+	return ""
+}
+
+// labelFor returns the value of the metric label of the rate-limit state that applies to requests for the owner.
+func (c *credential) labelFor(owner string) string {
+	if key := c.stateKey(owner); key != "" {
+		return c.name + "/" + key
+	}
+	return c.name
+}
+
+func (c *credential) state(owner string) rateLimitState {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if state, found := c.states[c.stateKey(owner)]; found {
+		return *state
+	}
+	return rateLimitState{}
+}
+
+// updateState runs update with the rate-limit state that applies to requests for the owner while holding the lock.
+func (c *credential) updateState(owner string, update func(state *rateLimitState)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	key := c.stateKey(owner)
+	state, found := c.states[key]
+	if !found {
+		state = &rateLimitState{}
+		c.states[key] = state
+	}
+	update(state)
+}
+
+// isRateLimited returns the error that caused the credential to be rate-limited for the owner, if the reset time has
+// not passed yet.
+func (c *credential) isRateLimited(owner string, now time.Time) *RateLimitError {
+	state := c.state(owner)
+	if state.rateLimitedUntil != nil && now.Before(state.rateLimitedUntil.Info.Reset) {
+		return state.rateLimitedUntil
+	}
+	return nil
+}
+
+// remaining returns the amount of requests that are left for the credential. If nothing is known about the credential,
+// or the last known information is outdated, math.MaxInt is returned.
+func (c *credential) remaining(owner string, now time.Time) int {
+	info := c.rateLimitInfo(owner, now)
+	if info == nil {
+		return math.MaxInt
+	}
+	return info.Remaining
+}
+
+// rateLimitInfo returns the last known rate-limit information of the credential, if it is not outdated.
+func (c *credential) rateLimitInfo(owner string, now time.Time) *RateLimitInfo {
+	state := c.state(owner)
+	if state.lastRateLimit == nil || !now.Before(state.lastRateLimit.Reset) {
+		return nil
+	}
+	info := *state.lastRateLimit
+	return &info
+}
+
+// observe updates the rate-limit state of the credential using the headers of a response.
+func (c *credential) observe(owner string, resp *http.Response) {
+	rateLimit := GetRateLimitInfo(resp)
+	if rateLimit == nil {
+		return
+	}
+
+	c.updateState(owner, func(state *rateLimitState) {
+		state.lastRateLimit = &rateLimit.Info
+		if rateLimit.Info.Remaining <= 0 {
+			state.rateLimitedUntil = rateLimit
+		}
+	})
+	metrics.GithubRateLimitRemaining.WithLabelValues(c.labelFor(owner)).Set(float64(rateLimit.Info.Remaining))
+}
+
+func (c *credential) setRateLimited(owner string, err *RateLimitError) {
+	c.updateState(owner, func(state *rateLimitState) {
+		state.rateLimitedUntil = err
+	})
+}
+
+// pickCredential returns the credential with the most remaining requests for the owner. If all credentials are
+// rate-limited, the RateLimitError with the earliest reset time is returned.
+func (g *GithubClient) pickCredential(owner string) (*credential, error) {
+	now := time.Now()
+
+	var best *credential
+	bestRemaining := -1
+	var earliestReset *RateLimitError
+	for _, cred := range g.credentials {
+		if rateLimitErr := cred.isRateLimited(owner, now); rateLimitErr != nil {
+			if earliestReset == nil || rateLimitErr.Info.Reset.Before(earliestReset.Info.Reset) {
+				earliestReset = rateLimitErr
+			}
+			continue
+		}
+
+		if remaining := cred.remaining(owner, now); remaining > bestRemaining {
+			best = cred
+			bestRemaining = remaining
+		}
+	}
+
+	if best == nil {
+		return nil, earliestReset
+	}
+	return best, nil
+}
+
+// do sends the request using the credential with the most remaining requests and evaluates the response. If the
+// request is rejected because the credential's rate limit is exceeded, the request is retried using the next
+// credential of the pool. No request is sent while a secondary rate limit is in effect and low priority requests are
+// deferred while the remaining requests are below the reserve. An error is returned if the response's status code is
+// neither 200 nor 304.
+func (g *GithubClient) do(ctx context.Context, req *http.Request, owner string) (*http.Response, error) {
+	var rateLimitErr *RateLimitError
+	for range g.credentials {
+		if err := g.isSecondaryRateLimited(); err != nil {
+			return nil, err
+		}
+
+		cred, err := g.pickCredential(owner)
+		if err != nil {
+			return nil, err
+		}
+
+		// the credential with the most remaining requests has been picked, if it is below the reserve, all are
+		if err := g.checkReserve(ctx, cred, owner); err != nil {
+			return nil, err
+		}
+
+		attempt := req.Clone(ctx)
+		if err := setAuthorizationHeader(ctx, attempt, cred, owner); err != nil {
+			return nil, err
+		}
+
+		resp, err := g.httpClient.Do(attempt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+		cred.observe(owner, resp)
+
+		// conditional requests are answered with 304 if nothing changed
+		if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNotModified {
+			return resp, nil
+		}
+
+		err = g.evaluateAndTransformError(resp, cred, owner)
+		_ = resp.Body.Close()
+
+		if !errors.As(err, &rateLimitErr) {
+			return nil, err
+		}
+	}
+
+	// all credentials have been tried
+	return nil, rateLimitErr
+}
+
+func setAuthorizationHeader(ctx context.Context, req *http.Request, cred *credential, owner string) error {
+	if cred.source == nil {
+		return nil
+	}
+
+	token, err := cred.source.Token(ctx, owner)
+	if err != nil {
+		return fmt.Errorf("could not get token for owner %q: %w", owner, err)
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+	return nil
+}
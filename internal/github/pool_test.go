@@ -0,0 +1,187 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestGithubClient_do(t *testing.T) {
+	reset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	remaining := map[string]int{
+		"Bearer token-a": 0,
+		"Bearer token-b": 100,
+	}
+	var used []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		used = append(used, auth)
+
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Reset", reset)
+		if remaining[auth] <= 0 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		remaining[auth]--
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining[auth]))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewGithubClient(server.Client(), NewStaticTokenSource("token-a"), NewStaticTokenSource("token-b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	send := func() error {
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+		resp, err := client.do(context.Background(), req, "owner")
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		return err
+	}
+
+	// token-a is exhausted, the request is expected to be retried using token-b
+	if err := send(); err != nil {
+		t.Fatalf("do() error = %v", err)
+	}
+	if len(used) != 2 || used[0] != "Bearer token-a" || used[1] != "Bearer token-b" {
+		t.Fatalf("expected retry with second token, got %v", used)
+	}
+
+	// token-a is known to be rate-limited, token-b is expected to be chosen right away
+	used = nil
+	if err := send(); err != nil {
+		t.Fatalf("do() error = %v", err)
+	}
+	if len(used) != 1 || used[0] != "Bearer token-b" {
+		t.Fatalf("expected token-b to be used, got %v", used)
+	}
+
+	// both tokens are exhausted
+	remaining["Bearer token-b"] = 0
+	_ = send()
+	err = send()
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("expected RateLimitError, got %v", err)
+	}
+}
+
+func TestGithubClient_doThrottled(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "50")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewGithubClient(server.Client(), NewStaticTokenSource("token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetRateLimitReserve(100)
+
+	send := func(ctx context.Context) error {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		resp, err := client.do(ctx, req, "owner")
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		return err
+	}
+
+	// nothing is known about the remaining requests yet
+	if err := send(WithLowPriority(context.Background())); err != nil {
+		t.Fatalf("do() error = %v", err)
+	}
+
+	var throttledErr *ThrottledError
+	if err := send(WithLowPriority(context.Background())); !errors.As(err, &throttledErr) || !throttledErr.Until.Equal(reset) {
+		t.Fatalf("expected ThrottledError until %v, got %v", reset, err)
+	}
+
+	// urgent requests are allowed to use the reserve
+	if err := send(context.Background()); err != nil {
+		t.Fatalf("do() error = %v", err)
+	}
+}
+
+type perOwnerTokenSource struct{}
+
+func (perOwnerTokenSource) Token(_ context.Context, owner string) (string, error) {
+	return "token-" + owner, nil
+}
+
+func (perOwnerTokenSource) hasPerOwnerRateLimits() bool {
+	return true
+}
+
+func TestGithubClient_doPerOwnerRateLimits(t *testing.T) {
+	reset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Reset", reset)
+		if r.Header.Get("Authorization") == "Bearer token-exhausted" {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "100")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewGithubClient(server.Client(), perOwnerTokenSource{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	send := func(owner string) error {
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+		resp, err := client.do(context.Background(), req, owner)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		return err
+	}
+
+	var rateLimitErr *RateLimitError
+	if err := send("exhausted"); !errors.As(err, &rateLimitErr) {
+		t.Fatalf("expected RateLimitError, got %v", err)
+	}
+
+	// the rate limit of one installation must not affect the others
+	if err := send("other"); err != nil {
+		t.Fatalf("do() error = %v", err)
+	}
+}
+
+func TestNewCredentials(t *testing.T) {
+	first := newCredentials([]TokenSource{NewStaticTokenSource("token-a"), NewStaticTokenSource("token-b")})
+	second := newCredentials([]TokenSource{NewStaticTokenSource("token-b")})
+
+	if first[0].name == first[1].name {
+		t.Errorf("expected distinct names for distinct tokens, got %q", first[0].name)
+	}
+
+	// the name must not depend on the position of the token in the pool of a client
+	if first[1].name != second[0].name {
+		t.Errorf("expected stable name for the same token, got %q and %q", first[1].name, second[0].name)
+	}
+
+	if anonymous := newCredentials(nil); anonymous[0].name != "anonymous" {
+		t.Errorf("expected anonymous credential, got %q", anonymous[0].name)
+	}
+}
@@ -0,0 +1,84 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ListRepositories returns all repositories of the given organization. If no organization with the given name exists,
+// the repositories of the user with the given name are returned.
+func (g *GithubClient) ListRepositories(ctx context.Context, owner string) ([]Repository, error) {
+	ret, err := g.listRepositories(ctx, fmt.Sprintf("https://api.github.com/orgs/%s/repos", url.PathEscape(owner)), owner)
+	if errors.Is(err, ErrNotFound) {
+		ret, err = g.listRepositories(ctx, fmt.Sprintf("https://api.github.com/users/%s/repos", url.PathEscape(owner)), owner)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not list repositories of %q: %w", owner, err)
+	}
+	return ret, nil
+}
+
+func (g *GithubClient) listRepositories(ctx context.Context, endpoint, owner string) ([]Repository, error) {
+	parsedURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	page := 1
+	hasNextPage := true
+	var ret []Repository
+
+	for hasNextPage {
+		params := url.Values{}
+		params.Add("per_page", "100")
+		params.Add("page", strconv.Itoa(page))
+		params.Add("type", "all")
+		parsedURL.RawQuery = params.Encode()
+
+		parsed, linkHeader, err := g.getRepositoryPage(ctx, parsedURL.String(), owner)
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, parsed...)
+		hasNextPage = linkHeader != "" && strings.Contains(linkHeader, "rel=\"next\"")
+		page++
+	}
+
+	return ret, nil
+}
+
+func (g *GithubClient) getRepositoryPage(ctx context.Context, endpoint, owner string) ([]Repository, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := g.do(ctx, req, owner)
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var parsed []Repository
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, "", fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	return parsed, resp.Header.Get("Link"), nil
+}
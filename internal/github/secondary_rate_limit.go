@@ -0,0 +1,70 @@
+package github
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultSecondaryRateLimitBackoff is the time to wait after hitting a secondary rate limit if GitHub does not send a
+// Retry-After header. GitHub advises to wait at least one minute.
+const defaultSecondaryRateLimitBackoff = time.Minute
+
+// isSecondaryRateLimited returns the error of the last secondary rate limit that was hit, if its deadline has not
+// passed yet. Secondary rate limits are honored for all requests of the client.
+func (g *GithubClient) isSecondaryRateLimited() error {
+	g.secondaryRateLimitMutex.RLock()
+	defer g.secondaryRateLimitMutex.RUnlock()
+	if g.secondaryRateLimitedUntil != nil && time.Now().Before(g.secondaryRateLimitedUntil.RetryAfter) {
+		return g.secondaryRateLimitedUntil
+	}
+	return nil
+}
+
+func (g *GithubClient) setSecondaryRateLimited(err *SecondaryRateLimitError) {
+	g.secondaryRateLimitMutex.Lock()
+	defer g.secondaryRateLimitMutex.Unlock()
+	// concurrent requests may report different deadlines, keep the latest one
+	if g.secondaryRateLimitedUntil == nil || err.RetryAfter.After(g.secondaryRateLimitedUntil.RetryAfter) {
+		g.secondaryRateLimitedUntil = err
+	}
+}
+
+// getSecondaryRateLimitInfo returns a SecondaryRateLimitError if the response indicates a secondary rate limit, which
+// is either signaled by a Retry-After header or by the message of the response.
+func getSecondaryRateLimitInfo(resp *http.Response) *SecondaryRateLimitError {
+	if retryAfter, found := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); found {
+		return &SecondaryRateLimitError{RetryAfter: retryAfter}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return nil
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || strings.Contains(strings.ToLower(string(body)), "secondary rate limit") {
+		return &SecondaryRateLimitError{RetryAfter: time.Now().Add(defaultSecondaryRateLimitBackoff)}
+	}
+
+	return nil
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either given in seconds or as HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return now.Add(time.Duration(seconds) * time.Second), true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return date, true
+	}
+
+	return time.Time{}, false
+}
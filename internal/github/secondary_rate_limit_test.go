@@ -0,0 +1,84 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 5, 30, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		value     string
+		want      time.Time
+		wantFound bool
+	}{
+		{
+			name:      "seconds",
+			value:     "120",
+			want:      now.Add(2 * time.Minute),
+			wantFound: true,
+		},
+		{
+			name:      "http date",
+			value:     "Fri, 30 May 2025 15:05:00 GMT",
+			want:      now.Add(5 * time.Minute),
+			wantFound: true,
+		},
+		{
+			name:      "empty",
+			value:     "",
+			wantFound: false,
+		},
+		{
+			name:      "garbage",
+			value:     "soon",
+			wantFound: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := parseRetryAfter(tt.value, now)
+			if found != tt.wantFound || !got.Equal(tt.want) {
+				t.Errorf("parseRetryAfter() = %v, %v, want %v, %v", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestGithubClient_doSecondaryRateLimit(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message": "You have exceeded a secondary rate limit."}`))
+	}))
+	defer server.Close()
+
+	client, err := NewGithubClient(server.Client(), NewStaticTokenSource("token-a"), NewStaticTokenSource("token-b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+		_, err = client.do(context.Background(), req, "owner")
+
+		retryAt, isRateLimited := RetryAt(err)
+		var secondaryRateLimitErr *SecondaryRateLimitError
+		if !isRateLimited || !errors.As(err, &secondaryRateLimitErr) || time.Until(retryAt) < 50*time.Second {
+			t.Fatalf("expected SecondaryRateLimitError, got %v", err)
+		}
+	}
+
+	// the deadline is honored for all subsequent requests, regardless of the token
+	if requests.Load() != 1 {
+		t.Errorf("expected a single request to be sent, got %d", requests.Load())
+	}
+}
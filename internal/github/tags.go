@@ -0,0 +1,65 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/soerenschneider/gollum/internal/metrics"
+)
+
+const maxTagDereferences = 3
+
+// ResolveTag returns the SHA of the commit a tag points to. Annotated tags are dereferenced.
+func (g *GithubClient) ResolveTag(ctx context.Context, owner, repo, tag string) (string, error) {
+	endpoint := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/ref/tags/%s", owner, repo, url.PathEscape(tag))
+	for range maxTagDereferences {
+		ref, err := g.getGitRef(ctx, owner, repo, endpoint)
+		if err != nil {
+			metrics.GithubRequestErrors.WithLabelValues(owner, repo, "tags").Inc()
+			return "", fmt.Errorf("could not resolve tag %q: %w", tag, err)
+		}
+
+		switch ref.Object.Type {
+		case "commit":
+			return ref.Object.SHA, nil
+		case "tag":
+			endpoint = fmt.Sprintf("https://api.github.com/repos/%s/%s/git/tags/%s", owner, repo, ref.Object.SHA)
+		default:
+			return "", fmt.Errorf("tag %q points to unsupported object type %q", tag, ref.Object.Type)
+		}
+	}
+
+	return "", fmt.Errorf("could not resolve tag %q: too many nested tags", tag)
+}
+
+func (g *GithubClient) getGitRef(ctx context.Context, owner, repo, endpoint string) (*gitRef, error) {
+	metrics.GithubRequestsTotal.WithLabelValues(owner, repo).Inc()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := g.do(ctx, req, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := &gitRef{}
+	if err := json.Unmarshal(data, ref); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return ref, nil
+}
@@ -0,0 +1,60 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/soerenschneider/gollum/internal/metrics"
+)
+
+type priorityKey struct{}
+
+// ThrottledError is returned for low priority requests while the remaining requests of all tokens are below the
+// configured reserve. The request can be retried after Until.
+type ThrottledError struct {
+	Remaining int
+	Until     time.Time
+}
+
+// Error implements the error interface for ThrottledError.
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("Request deferred to preserve rate limit reserve. Remaining: %d, Deferred until: %s", e.Remaining, e.Until)
+}
+
+// WithLowPriority marks all requests using the returned context as non-urgent. Those requests are deferred once the
+// remaining requests drop below the reserve, so quota is kept for urgent requests and other tools sharing the token.
+func WithLowPriority(ctx context.Context) context.Context {
+	return context.WithValue(ctx, priorityKey{}, true)
+}
+
+func isLowPriority(ctx context.Context) bool {
+	lowPriority, _ := ctx.Value(priorityKey{}).(bool)
+	return lowPriority
+}
+
+// SetRateLimitReserve sets the amount of requests that are reserved for urgent requests. A value <= 0 disables
+// throttling.
+func (g *GithubClient) SetRateLimitReserve(reserve int) {
+	g.rateLimitReserve.Store(int64(reserve))
+}
+
+// checkReserve returns a ThrottledError if the request is not urgent and the credential's remaining requests dropped
+// below the reserve.
+func (g *GithubClient) checkReserve(ctx context.Context, cred *credential, owner string) error {
+	reserve := int(g.rateLimitReserve.Load())
+	if reserve <= 0 || !isLowPriority(ctx) {
+		return nil
+	}
+
+	info := cred.rateLimitInfo(owner, time.Now())
+	if info == nil || info.Remaining >= reserve {
+		return nil
+	}
+
+	metrics.GithubThrottledRequests.Inc()
+	return &ThrottledError{
+		Remaining: info.Remaining,
+		Until:     info.Reset,
+	}
+}
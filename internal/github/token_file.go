@@ -0,0 +1,123 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const defaultTokenFilePollInterval = 30 * time.Second
+
+// TokenFileWatcher watches a file containing a token, e.g. a mounted Secret, and swaps the token of the client once
+// the content of the file changes. Kubernetes updates mounted Secrets by swapping symlinks, therefore the file's
+// content is polled instead of relying on filesystem events.
+type TokenFileWatcher struct {
+	path         string
+	pollInterval time.Duration
+	client       *GithubClient
+	token        string
+	// info is the identity of the current token, if known
+	info *TokenInfo
+}
+
+func NewTokenFileWatcher(path string, client *GithubClient) (*TokenFileWatcher, error) {
+	if client == nil {
+		return nil, errors.New("empty client passed")
+	}
+
+	token, err := ReadTokenFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenFileWatcher{
+		path:         path,
+		pollInterval: defaultTokenFilePollInterval,
+		client:       client,
+		token:        token,
+	}, nil
+}
+
+// ReadTokenFile reads the token from the given file, surrounding whitespace is removed.
+func ReadTokenFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("could not read token file: %w", err)
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %q is empty", path)
+	}
+	return token, nil
+}
+
+// Start polls the token file until the context is canceled. It implements the manager.Runnable interface.
+func (w *TokenFileWatcher) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("token-file-watcher")
+
+	// the identity of the initial token is only used for comparison, errors are not fatal
+	info, err := w.client.GetTokenInfo(ctx, "")
+	if err != nil {
+		logger.Error(err, "could not get identity of GitHub token")
+	}
+	w.info = info
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := w.reload(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// reload swaps the token of the client if the content of the token file changed. It returns whether the token has
+// been swapped. Only errors that make further reloads impossible are returned.
+func (w *TokenFileWatcher) reload(ctx context.Context) (bool, error) {
+	logger := log.FromContext(ctx).WithName("token-file-watcher")
+
+	token, err := ReadTokenFile(w.path)
+	if err != nil {
+		logger.Error(err, "could not read token file", "path", w.path)
+		return false, nil
+	}
+
+	if token == w.token {
+		return false, nil
+	}
+
+	if err := w.client.SetToken(token); err != nil {
+		return false, err
+	}
+	w.token = token
+	logger.Info("Swapped GitHub token after token file changed", "path", w.path)
+
+	info, err := w.client.GetTokenInfo(ctx, "")
+	if err != nil {
+		logger.Error(err, "could not get identity of new GitHub token")
+		return true, nil
+	}
+
+	if w.info != nil && !w.info.Equals(info) {
+		logger.Info("WARNING, identity or scopes of the new GitHub token differ", "previous_login", w.info.Login, "login", info.Login, "previous_scopes", w.info.Scopes, "scopes", info.Scopes)
+	}
+	w.info = info
+	return true, nil
+}
+
+// NeedLeaderElection returns false, as each replica needs to swap its token.
+func (w *TokenFileWatcher) NeedLeaderElection() bool {
+	return false
+}
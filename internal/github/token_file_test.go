@@ -0,0 +1,132 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTokenFileWatcher_reload(t *testing.T) {
+	logins := map[string]string{
+		"Bearer token-a": "alice",
+		"Bearer token-b": "alice",
+	}
+	var used []string
+
+	// all requests are answered by the handler instead of api.github.com
+	client, err := NewGithubClient(&http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		auth := req.Header.Get("Authorization")
+		used = append(used, auth)
+
+		recorder := httptest.NewRecorder()
+		recorder.Header().Set("X-OAuth-Scopes", "repo, read:packages")
+		_, _ = recorder.WriteString(`{"login": "` + logins[auth] + `"}`)
+		return recorder.Result(), nil
+	})}, NewStaticTokenSource("token-a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "token")
+	writeToken := func(token string) {
+		if err := os.WriteFile(path, []byte(token), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeToken("token-a\n")
+
+	watcher, err := NewTokenFileWatcher(path, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// unchanged token, only surrounding whitespace differs
+	writeToken("  token-a ")
+	if swapped, err := watcher.reload(context.Background()); err != nil || swapped {
+		t.Fatalf("reload() = %v, %v, expected no-op", swapped, err)
+	}
+	if len(used) != 0 {
+		t.Fatalf("expected no requests for unchanged token, got %v", used)
+	}
+
+	writeToken("token-b\n")
+	if swapped, err := watcher.reload(context.Background()); err != nil || !swapped {
+		t.Fatalf("reload() = %v, %v, expected token to be swapped", swapped, err)
+	}
+
+	// the identity of the new token is looked up using the new token
+	if len(used) != 1 || used[0] != "Bearer token-b" {
+		t.Fatalf("expected request using token-b, got %v", used)
+	}
+	if watcher.info == nil || watcher.info.Login != "alice" {
+		t.Errorf("expected identity of new token to be stored, got %v", watcher.info)
+	}
+
+	// subsequent requests of the client use the new token
+	used = nil
+	if _, err := client.GetTokenInfo(context.Background(), ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(used) != 1 || used[0] != "Bearer token-b" {
+		t.Errorf("expected client to use token-b, got %v", used)
+	}
+
+	// an empty file is ignored, the current token is kept
+	writeToken("")
+	if swapped, err := watcher.reload(context.Background()); err != nil || swapped {
+		t.Fatalf("reload() = %v, %v, expected empty file to be ignored", swapped, err)
+	}
+}
+
+func TestGithubClient_SetToken(t *testing.T) {
+	client, err := NewGithubClient(nil, NewStaticTokenSource("token-a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.unauthorized.Store(true)
+
+	if err := client.SetToken("token-b"); err != nil {
+		t.Fatalf("SetToken() error = %v", err)
+	}
+
+	token, _ := client.credentials[0].source.Token(context.Background(), "")
+	if token != "token-b" || client.unauthorized.Load() {
+		t.Errorf("SetToken() token = %q, unauthorized = %v", token, client.unauthorized.Load())
+	}
+
+	pool, _ := NewGithubClient(nil, NewStaticTokenSource("token-a"), NewStaticTokenSource("token-b"))
+	if err := pool.SetToken("token-c"); err == nil {
+		t.Errorf("expected error for client using a token pool")
+	}
+}
+
+func TestTokenInfo_Equals(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  *TokenInfo
+		equal bool
+	}{
+		{name: "both nil", equal: true},
+		{name: "one nil", a: &TokenInfo{Login: "alice"}},
+		{name: "same", a: &TokenInfo{Login: "alice", Scopes: []string{"repo"}}, b: &TokenInfo{Login: "alice", Scopes: []string{"repo"}}, equal: true},
+		{name: "different login", a: &TokenInfo{Login: "alice"}, b: &TokenInfo{Login: "bob"}},
+		{name: "different scopes", a: &TokenInfo{Login: "alice", Scopes: []string{"repo"}}, b: &TokenInfo{Login: "alice", Scopes: []string{"read:packages", "repo"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Equals(tt.b); got != tt.equal {
+				t.Errorf("Equals() = %v, want %v", got, tt.equal)
+			}
+		})
+	}
+}
@@ -7,9 +7,11 @@ import (
 )
 
 const (
-	namespace       = "gollum"
-	subsystemGitHub = "github"
-	subsystemTekton = "tekton"
+	namespace        = "gollum"
+	subsystemGitHub  = "github"
+	subsystemGitea   = "gitea"
+	subsystemTekton  = "tekton"
+	subsystemWebhook = "webhook"
 )
 
 var (
@@ -19,6 +21,19 @@ var (
 		Help:      "Seconds after a repo gets requeued",
 	}, []string{"owner", "repo"})
 
+	PollIntervalSeconds = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "poll_interval_seconds",
+		Help:      "The interval in seconds all repositories are polled in to stay within the request budget",
+	})
+
+	WebhookEventsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystemWebhook,
+		Name:      "events_total",
+		Help:      "Total number of received webhook events",
+	}, []string{"event", "result"})
+
 	LastReleaseCheck = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: namespace,
 		Subsystem: subsystemGitHub,
@@ -54,6 +69,34 @@ var (
 		Help:      "The total amount of failed GitHub requests",
 	}, []string{"owner", "repo", "url"})
 
+	GithubRateLimitRemaining = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystemGitHub,
+		Name:      "rate_limit_remaining",
+		Help:      "The amount of remaining GitHub requests per token of the token pool",
+	}, []string{"token"})
+
+	GithubThrottledRequests = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystemGitHub,
+		Name:      "throttled_requests_total",
+		Help:      "The total amount of non-urgent GitHub requests that have been deferred to preserve the rate limit reserve",
+	})
+
+	GiteaRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystemGitea,
+		Name:      "requests_total",
+		Help:      "The total amount of Gitea/Forgejo requests",
+	}, []string{"owner", "repo"})
+
+	GiteaRequestErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystemGitea,
+		Name:      "request_errors_total",
+		Help:      "The total amount of failed Gitea/Forgejo requests",
+	}, []string{"owner", "repo", "url"})
+
 	PipelineRunCreationErrors = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: namespace,
 		Subsystem: subsystemTekton,
@@ -71,11 +114,17 @@ var (
 
 func init() {
 	metrics.Registry.MustRegister(RequeueAfter)
+	metrics.Registry.MustRegister(PollIntervalSeconds)
+	metrics.Registry.MustRegister(WebhookEventsTotal)
 	metrics.Registry.MustRegister(LastReleaseCheck)
 	metrics.Registry.MustRegister(FilteredReleasesTotal)
 	metrics.Registry.MustRegister(ReleasesAvailableTotal)
 	metrics.Registry.MustRegister(GithubRequestsTotal)
 	metrics.Registry.MustRegister(GithubRequestErrors)
+	metrics.Registry.MustRegister(GithubRateLimitRemaining)
+	metrics.Registry.MustRegister(GithubThrottledRequests)
+	metrics.Registry.MustRegister(GiteaRequestsTotal)
+	metrics.Registry.MustRegister(GiteaRequestErrors)
 	metrics.Registry.MustRegister(PipelineRunCreationErrors)
 	metrics.Registry.MustRegister(PipelineRunsCreated)
 }
@@ -0,0 +1,221 @@
+package receiver
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/soerenschneider/gollum/internal/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// maxPayloadSize is the maximum size of a webhook payload GitHub sends.
+	maxPayloadSize = 25 * 1024 * 1024
+
+	signatureHeader = "X-Hub-Signature-256"
+	eventHeader     = "X-GitHub-Event"
+	signaturePrefix = "sha256="
+
+	defaultPath = "/webhook/github"
+)
+
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// Trigger immediately reconciles all Repositories that watch the given GitHub repository.
+type Trigger interface {
+	TriggerReconcile(ctx context.Context, owner, repo string) (int, error)
+}
+
+// SecretSource returns the secret that is used to validate the signature of webhook payloads.
+type SecretSource func(ctx context.Context) ([]byte, error)
+
+// WebhookReceiver accepts GitHub "release" and "registry_package" webhook events and triggers an immediate
+// reconcile of the Repositories that watch the repository the event belongs to. Polling remains the fallback for
+// missed events.
+type WebhookReceiver struct {
+	address string
+	secret  SecretSource
+	trigger Trigger
+}
+
+func NewWebhookReceiver(address string, secret SecretSource, trigger Trigger) (*WebhookReceiver, error) {
+	if address == "" {
+		return nil, errors.New("empty address passed")
+	}
+	if secret == nil {
+		return nil, errors.New("empty secret source passed")
+	}
+	if trigger == nil {
+		return nil, errors.New("empty trigger passed")
+	}
+
+	return &WebhookReceiver{
+		address: address,
+		secret:  secret,
+		trigger: trigger,
+	}, nil
+}
+
+// Start serves the webhook endpoint until the context is canceled. It implements the manager.Runnable interface.
+func (w *WebhookReceiver) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("webhook-receiver")
+
+	mux := http.NewServeMux()
+	mux.Handle(defaultPath, w)
+	server := &http.Server{
+		Addr:              w.address,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(_ net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		logger.Info("Starting webhook receiver", "address", w.address, "path", defaultPath)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errChan <- err
+		}
+		close(errChan)
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	}
+}
+
+// NeedLeaderElection returns true, as only the leader is able to reconcile the triggered Repositories.
+func (w *WebhookReceiver) NeedLeaderElection() bool {
+	return true
+}
+
+func (w *WebhookReceiver) ServeHTTP(writer http.ResponseWriter, req *http.Request) {
+	logger := log.FromContext(req.Context()).WithName("webhook-receiver")
+
+	if req.Method != http.MethodPost {
+		http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(req.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(writer, "could not read payload", http.StatusBadRequest)
+		return
+	}
+
+	secret, err := w.secret(req.Context())
+	if err != nil {
+		logger.Error(err, "could not get webhook secret")
+		http.Error(writer, "could not validate signature", http.StatusInternalServerError)
+		return
+	}
+
+	if err := validateSignature(payload, req.Header.Get(signatureHeader), secret); err != nil {
+		metrics.WebhookEventsTotal.WithLabelValues(req.Header.Get(eventHeader), "invalid_signature").Inc()
+		http.Error(writer, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	eventType := req.Header.Get(eventHeader)
+	owner, repo, isRelevant, err := parseEvent(eventType, payload)
+	if err != nil {
+		metrics.WebhookEventsTotal.WithLabelValues(eventType, "invalid_payload").Inc()
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !isRelevant {
+		metrics.WebhookEventsTotal.WithLabelValues(eventType, "ignored").Inc()
+		writer.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	triggered, err := w.trigger.TriggerReconcile(req.Context(), owner, repo)
+	if err != nil {
+		logger.Error(err, "could not trigger reconcile", "owner", owner, "repo", repo)
+		metrics.WebhookEventsTotal.WithLabelValues(eventType, "error").Inc()
+		http.Error(writer, "could not trigger reconcile", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("Received webhook event", "event", eventType, "owner", owner, "repo", repo, "triggered", triggered)
+	metrics.WebhookEventsTotal.WithLabelValues(eventType, "triggered").Inc()
+	writer.WriteHeader(http.StatusAccepted)
+	_, _ = fmt.Fprintf(writer, "triggered %d repositories\n", triggered)
+}
+
+// validateSignature checks the HMAC-SHA256 signature GitHub sends in the X-Hub-Signature-256 header.
+func validateSignature(payload []byte, signature string, secret []byte) error {
+	if len(secret) == 0 {
+		return errors.New("no webhook secret configured")
+	}
+
+	signature, found := strings.CutPrefix(signature, signaturePrefix)
+	if !found {
+		return ErrInvalidSignature
+	}
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+type eventPayload struct {
+	Action     string `json:"action"`
+	Repository struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+}
+
+// parseEvent returns the repository of the event and whether the event may indicate new artifacts.
+func parseEvent(eventType string, payload []byte) (string, string, bool, error) {
+	switch eventType {
+	case "release", "registry_package", "package":
+	default:
+		// e.g. "ping", which is sent after the webhook has been created
+		return "", "", false, nil
+	}
+
+	var parsed eventPayload
+	if err := json.Unmarshal(payload, &parsed); err != nil {
+		return "", "", false, fmt.Errorf("could not parse payload: %w", err)
+	}
+
+	if parsed.Action == "deleted" || parsed.Action == "unpublished" {
+		return "", "", false, nil
+	}
+
+	if parsed.Repository.Owner.Login == "" || parsed.Repository.Name == "" {
+		return "", "", false, errors.New("payload is missing the repository")
+	}
+
+	return parsed.Repository.Owner.Login, parsed.Repository.Name, true, nil
+}
@@ -0,0 +1,130 @@
+package receiver
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type fakeTrigger struct {
+	owner string
+	repo  string
+	calls int
+}
+
+func (f *fakeTrigger) TriggerReconcile(_ context.Context, owner, repo string) (int, error) {
+	f.owner = owner
+	f.repo = repo
+	f.calls++
+	return 1, nil
+}
+
+func sign(payload, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhookReceiver_ServeHTTP(t *testing.T) {
+	const secret = "It's a Secret to Everybody"
+	const releasePayload = `{"action":"published","release":{"tag_name":"v1.0.0"},"repository":{"name":"tunnelguard","owner":{"login":"soerenschneider"}}}`
+
+	tests := []struct {
+		name        string
+		method      string
+		event       string
+		payload     string
+		signature   string
+		wantStatus  int
+		wantTrigger bool
+	}{
+		{
+			name:        "release event",
+			method:      http.MethodPost,
+			event:       "release",
+			payload:     releasePayload,
+			signature:   sign(releasePayload, secret),
+			wantStatus:  http.StatusAccepted,
+			wantTrigger: true,
+		},
+		{
+			name:        "registry package event",
+			method:      http.MethodPost,
+			event:       "registry_package",
+			payload:     releasePayload,
+			signature:   sign(releasePayload, secret),
+			wantStatus:  http.StatusAccepted,
+			wantTrigger: true,
+		},
+		{
+			name:       "invalid signature",
+			method:     http.MethodPost,
+			event:      "release",
+			payload:    releasePayload,
+			signature:  sign(releasePayload, "wrong"),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "missing signature",
+			method:     http.MethodPost,
+			event:      "release",
+			payload:    releasePayload,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "ping event",
+			method:     http.MethodPost,
+			event:      "ping",
+			payload:    `{"zen":"Keep it logically awesome."}`,
+			signature:  sign(`{"zen":"Keep it logically awesome."}`, secret),
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "deleted release",
+			method:     http.MethodPost,
+			event:      "release",
+			payload:    `{"action":"deleted","repository":{"name":"tunnelguard","owner":{"login":"soerenschneider"}}}`,
+			signature:  sign(`{"action":"deleted","repository":{"name":"tunnelguard","owner":{"login":"soerenschneider"}}}`, secret),
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "wrong method",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trigger := &fakeTrigger{}
+			receiver, err := NewWebhookReceiver(":0", func(ctx context.Context) ([]byte, error) {
+				return []byte(secret), nil
+			}, trigger)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(tt.method, defaultPath, strings.NewReader(tt.payload))
+			req.Header.Set(eventHeader, tt.event)
+			if tt.signature != "" {
+				req.Header.Set(signatureHeader, tt.signature)
+			}
+			recorder := httptest.NewRecorder()
+			receiver.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if (trigger.calls > 0) != tt.wantTrigger {
+				t.Errorf("triggered = %v, want %v", trigger.calls > 0, tt.wantTrigger)
+			}
+			if tt.wantTrigger && (trigger.owner != "soerenschneider" || trigger.repo != "tunnelguard") {
+				t.Errorf("triggered %s/%s", trigger.owner, trigger.repo)
+			}
+		})
+	}
+}
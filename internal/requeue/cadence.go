@@ -0,0 +1,101 @@
+package requeue
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+const (
+	// cadenceSampleSize is the maximum amount of most recent releases that are considered to learn the cadence.
+	cadenceSampleSize = 10
+	// dormancyBackoffDivisor controls how fast polling backs off for dormant repositories: the interval grows by one
+	// unit for each dormancyBackoffDivisor units the repository has not released.
+	dormancyBackoffDivisor = 10
+)
+
+// CadenceRequeue learns the release frequency of a repository from the timestamps of its releases. It polls more
+// often around the time the next release is expected and backs off for dormant repositories, always within the
+// configured bounds.
+type CadenceRequeue struct {
+	minInterval time.Duration
+	maxInterval time.Duration
+	timeSource  timeSource
+}
+
+// NewCadenceRequeue creates a new CadenceRequeue.
+//
+// Parameters:
+//   - minInterval: The shortest interval a repository is polled in, used around the expected release time.
+//   - maxInterval: The longest interval a repository is polled in, used for dormant repositories.
+//
+// Returns:
+//   - A pointer to a CadenceRequeue.
+//   - An error if the bounds are not positive or minInterval exceeds maxInterval.
+func NewCadenceRequeue(minInterval, maxInterval time.Duration) (*CadenceRequeue, error) {
+	if minInterval <= 0 || maxInterval <= 0 {
+		return nil, errors.New("intervals must be > 0")
+	}
+	if minInterval > maxInterval {
+		return nil, errors.New("minInterval must be <= maxInterval")
+	}
+
+	return &CadenceRequeue{
+		minInterval: minInterval,
+		maxInterval: maxInterval,
+		timeSource:  &defaultTimeSource{},
+	}, nil
+}
+
+// Cadence returns the median duration between the most recent releases. It returns false if there are not enough
+// releases to learn the cadence.
+func Cadence(releases []time.Time) (time.Duration, bool) {
+	sorted := slices.Clone(releases)
+	slices.SortFunc(sorted, func(a, b time.Time) int {
+		return a.Compare(b)
+	})
+	sorted = slices.Compact(sorted)
+	if len(sorted) > cadenceSampleSize {
+		sorted = sorted[len(sorted)-cadenceSampleSize:]
+	}
+	if len(sorted) < 2 {
+		return 0, false
+	}
+
+	gaps := make([]time.Duration, 0, len(sorted)-1)
+	for i := 1; i < len(sorted); i++ {
+		gaps = append(gaps, sorted[i].Sub(sorted[i-1]))
+	}
+	slices.Sort(gaps)
+
+	return gaps[len(gaps)/2], true
+}
+
+// Interval returns the interval to poll a repository with the given release timestamps in. It returns false if the
+// cadence can not be learned yet.
+func (c *CadenceRequeue) Interval(releases []time.Time) (time.Duration, bool) {
+	cadence, ok := Cadence(releases)
+	if !ok {
+		return 0, false
+	}
+
+	now := c.timeSource.Now()
+	lastRelease := slices.MaxFunc(releases, func(a, b time.Time) int {
+		return a.Compare(b)
+	})
+	expectedRelease := lastRelease.Add(cadence)
+	// releases rarely happen exactly on time, poll often within this window around the expected release
+	window := cadence / 4
+
+	var interval time.Duration
+	switch {
+	case now.Before(expectedRelease.Add(-window)):
+		interval = expectedRelease.Add(-window).Sub(now)
+	case now.Before(expectedRelease.Add(window)):
+		interval = c.minInterval
+	default:
+		interval = now.Sub(lastRelease) / dormancyBackoffDivisor
+	}
+
+	return min(max(interval, c.minInterval), c.maxInterval), true
+}
@@ -0,0 +1,78 @@
+package requeue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCadenceRequeue_Interval(t *testing.T) {
+	now := time.Date(2025, 5, 30, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(days ...int) []time.Time {
+		var ret []time.Time
+		for _, d := range days {
+			ret = append(ret, now.AddDate(0, 0, -d))
+		}
+		return ret
+	}
+
+	tests := []struct {
+		name     string
+		releases []time.Time
+		want     time.Duration
+		wantOk   bool
+	}{
+		{
+			name:     "not enough releases",
+			releases: daysAgo(3),
+			wantOk:   false,
+		},
+		{
+			name:     "daily releases, next release expected",
+			releases: daysAgo(1, 2, 3, 4),
+			want:     15 * time.Minute,
+			wantOk:   true,
+		},
+		{
+			name:     "weekly releases, last release yesterday",
+			releases: daysAgo(1, 8, 15),
+			// expected in 6 days, window opens 1.75 days before
+			want:   24 * time.Hour,
+			wantOk: true,
+		},
+		{
+			name:     "weekly releases, last release 5 days ago",
+			releases: daysAgo(5, 12, 19),
+			want:     6 * time.Hour,
+			wantOk:   true,
+		},
+		{
+			name:     "daily releases, dormant for 5 days",
+			releases: daysAgo(5, 6, 7),
+			want:     12 * time.Hour,
+			wantOk:   true,
+		},
+		{
+			name:     "semi-annual releases, dormant for years",
+			releases: daysAgo(900, 1080, 1260),
+			want:     24 * time.Hour,
+			wantOk:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCadenceRequeue(15*time.Minute, 24*time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.timeSource = &fakeTime{ret: now}
+
+			got, ok := c.Interval(tt.releases)
+			if ok != tt.wantOk {
+				t.Fatalf("Interval() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if got != tt.want {
+				t.Errorf("Interval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
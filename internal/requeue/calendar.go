@@ -0,0 +1,295 @@
+package requeue
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	minutesPerDay = 24 * 60
+	allWeekdays   = 1<<7 - 1
+
+	// calendarSearchLimit bounds the search for the next active time, so a calendar that is never active does not
+	// loop forever.
+	calendarSearchLimit = 2 * 366 * 24 * time.Hour
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Window is a time window that repeats on the given weekdays. A window whose end is before its start crosses
+// midnight and ends on the following day.
+type Window struct {
+	// Weekdays is a bitmask of the days the window starts on, with bit 0 being sunday.
+	Weekdays uint8
+	// From is the start of the window in minutes after midnight.
+	From int
+	// To is the end of the window in minutes after midnight.
+	To int
+}
+
+// ParseWindow parses a window such as "mon-fri 08:00-18:00", "sat,sun 22:00-06:00" or "09:00-17:00". If no weekdays
+// are given, the window is active on every day.
+func ParseWindow(spec string) (Window, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 || len(fields) > 2 {
+		return Window{}, fmt.Errorf("invalid window %q, expected '[weekdays] HH:MM-HH:MM'", spec)
+	}
+
+	ret := Window{Weekdays: allWeekdays}
+	if len(fields) == 2 {
+		weekdays, err := parseWeekdays(fields[0])
+		if err != nil {
+			return Window{}, err
+		}
+		ret.Weekdays = weekdays
+	}
+
+	fromPart, toPart, found := strings.Cut(fields[len(fields)-1], "-")
+	if !found {
+		return Window{}, fmt.Errorf("invalid window %q, expected '[weekdays] HH:MM-HH:MM'", spec)
+	}
+
+	var err error
+	if ret.From, err = parseTimeOfDay(fromPart); err != nil {
+		return Window{}, err
+	}
+	if ret.To, err = parseTimeOfDay(toPart); err != nil {
+		return Window{}, err
+	}
+	if ret.From == ret.To {
+		return Window{}, fmt.Errorf("window %q is empty", spec)
+	}
+	if ret.From == minutesPerDay {
+		return Window{}, fmt.Errorf("window %q can not start at 24:00", spec)
+	}
+
+	return ret, nil
+}
+
+// ParseWindows parses a list of windows separated by semicolons.
+func ParseWindows(spec string) ([]Window, error) {
+	var ret []Window
+	for _, part := range strings.Split(spec, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		window, err := ParseWindow(part)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, window)
+	}
+	return ret, nil
+}
+
+func parseWeekdays(spec string) (uint8, error) {
+	if spec == "*" {
+		return allWeekdays, nil
+	}
+
+	var ret uint8
+	for _, part := range strings.Split(strings.ToLower(spec), ",") {
+		fromPart, toPart, isRange := strings.Cut(part, "-")
+		from, found := weekdayNames[fromPart]
+		if !found {
+			return 0, fmt.Errorf("invalid weekday %q", fromPart)
+		}
+		to := from
+		if isRange {
+			if to, found = weekdayNames[toPart]; !found {
+				return 0, fmt.Errorf("invalid weekday %q", toPart)
+			}
+		}
+
+		// ranges such as "fri-mon" wrap around the end of the week
+		for day := from; ; day = (day + 1) % 7 {
+			ret |= 1 << day
+			if day == to {
+				break
+			}
+		}
+	}
+	return ret, nil
+}
+
+func parseTimeOfDay(spec string) (int, error) {
+	hourPart, minutePart, found := strings.Cut(spec, ":")
+	if !found {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", spec)
+	}
+
+	hour, err := strconv.Atoi(hourPart)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", spec)
+	}
+	minute, err := strconv.Atoi(minutePart)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", spec)
+	}
+
+	ret := hour*60 + minute
+	if hour < 0 || minute < 0 || minute > 59 || ret > minutesPerDay {
+		return 0, fmt.Errorf("time %q out of range", spec)
+	}
+	return ret, nil
+}
+
+// occurrence returns the start and end of the window that starts on the day of the given time. The bool is false if
+// the window is not active on that day.
+func (w Window) occurrence(day time.Time) (time.Time, time.Time, bool) {
+	if w.Weekdays&(1<<day.Weekday()) == 0 {
+		return time.Time{}, time.Time{}, false
+	}
+
+	year, month, dayOfMonth := day.Date()
+	start := time.Date(year, month, dayOfMonth, 0, w.From, 0, 0, day.Location())
+	end := time.Date(year, month, dayOfMonth, 0, w.To, 0, 0, day.Location())
+	if w.To < w.From {
+		end = time.Date(year, month, dayOfMonth+1, 0, w.To, 0, 0, day.Location())
+	}
+	return start, end, true
+}
+
+// CalendarRequeue defers requeues that would happen outside the configured windows or during a blackout to the next
+// time the calendar is active. All windows are evaluated in the configured time zone.
+type CalendarRequeue struct {
+	location  *time.Location
+	windows   []Window
+	blackouts []Blackout
+
+	timeSource timeSource
+}
+
+// NewCalendarRequeue creates a new CalendarRequeue.
+//
+// Parameters:
+//   - location: The time zone the windows are evaluated in. Defaults to UTC if nil.
+//   - windows: The windows requeues may happen in. If empty, requeues may happen at any time except blackouts.
+//   - blackouts: The periods no requeue may happen in, e.g. holidays.
+//
+// Returns:
+//   - A pointer to a CalendarRequeue.
+//   - An error if a window is invalid.
+func NewCalendarRequeue(location *time.Location, windows []Window, blackouts []Blackout) (*CalendarRequeue, error) {
+	if location == nil {
+		location = time.UTC
+	}
+
+	for _, window := range windows {
+		if window.Weekdays == 0 {
+			return nil, errors.New("window must be active on at least one weekday")
+		}
+		if window.From < 0 || window.From >= minutesPerDay || window.To < 0 || window.To > minutesPerDay || window.From == window.To {
+			return nil, errors.New("window out of range")
+		}
+	}
+
+	return &CalendarRequeue{
+		location:   location,
+		windows:    windows,
+		blackouts:  blackouts,
+		timeSource: &defaultTimeSource{},
+	}, nil
+}
+
+func (c *CalendarRequeue) Requeue(duration time.Duration) time.Duration {
+	now := c.timeSource.Now()
+	next, err := c.NextActive(now.Add(duration))
+	if err != nil {
+		return duration
+	}
+	return next.Sub(now)
+}
+
+// IsActive returns whether the given time is within a window and not within a blackout.
+func (c *CalendarRequeue) IsActive(t time.Time) bool {
+	_, active := c.activeUntil(t)
+	return active
+}
+
+// NextActive returns the given time if the calendar is active, otherwise the time the calendar becomes active next.
+func (c *CalendarRequeue) NextActive(t time.Time) (time.Time, error) {
+	t = t.In(c.location)
+	limit := t.Add(calendarSearchLimit)
+
+	for t.Before(limit) {
+		if blackout, found := c.blackoutAt(t); found {
+			t = blackout.In(c.location)
+			continue
+		}
+
+		if _, active := c.activeUntil(t); active {
+			return t, nil
+		}
+
+		next, found := c.nextWindowStart(t)
+		if !found {
+			break
+		}
+		t = next
+	}
+
+	return time.Time{}, errors.New("calendar is never active")
+}
+
+// activeUntil returns the end of the window the given time is in.
+func (c *CalendarRequeue) activeUntil(t time.Time) (time.Time, bool) {
+	t = t.In(c.location)
+	if _, found := c.blackoutAt(t); found {
+		return time.Time{}, false
+	}
+	if len(c.windows) == 0 {
+		return time.Time{}, true
+	}
+
+	var ret time.Time
+	found := false
+	// windows crossing midnight may have started on the previous day
+	for _, day := range []time.Time{t.AddDate(0, 0, -1), t} {
+		for _, window := range c.windows {
+			start, end, ok := window.occurrence(day)
+			if ok && !t.Before(start) && t.Before(end) && end.After(ret) {
+				ret = end
+				found = true
+			}
+		}
+	}
+	return ret, found
+}
+
+func (c *CalendarRequeue) nextWindowStart(t time.Time) (time.Time, bool) {
+	var ret time.Time
+	found := false
+	for offset := 0; offset <= 7; offset++ {
+		day := t.AddDate(0, 0, offset)
+		for _, window := range c.windows {
+			start, _, ok := window.occurrence(day)
+			if ok && start.After(t) && (!found || start.Before(ret)) {
+				ret = start
+				found = true
+			}
+		}
+	}
+	return ret, found
+}
+
+// blackoutAt returns the end of the blackout the given time is in.
+func (c *CalendarRequeue) blackoutAt(t time.Time) (time.Time, bool) {
+	for _, blackout := range c.blackouts {
+		if end, found := blackout.endIfContains(t); found {
+			return end, true
+		}
+	}
+	return time.Time{}, false
+}
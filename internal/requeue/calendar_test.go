@@ -0,0 +1,186 @@
+package requeue
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    Window
+		wantErr bool
+	}{
+		{spec: "08:00-22:00", want: Window{Weekdays: allWeekdays, From: 8 * 60, To: 22 * 60}},
+		{spec: "mon-fri 09:30-17:00", want: Window{Weekdays: 0b0111110, From: 9*60 + 30, To: 17 * 60}},
+		{spec: "sat,sun 22:00-06:00", want: Window{Weekdays: 0b1000001, From: 22 * 60, To: 6 * 60}},
+		{spec: "fri-mon 00:00-24:00", want: Window{Weekdays: 0b1100011, From: 0, To: minutesPerDay}},
+		{spec: "08:00-08:00", wantErr: true},
+		{spec: "mon 25:00-26:00", wantErr: true},
+		{spec: "someday 08:00-10:00", wantErr: true},
+		{spec: "08:00", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseWindow(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseWindow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseWindow() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalendarRequeue_Requeue(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+
+	mustParse := func(spec string) []Window {
+		windows, err := ParseWindows(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return windows
+	}
+
+	christmas, err := ParseBlackouts(strings.NewReader(`BEGIN:VCALENDAR
+BEGIN:VEVENT
+DTSTART;VALUE=DATE:20241225
+DTEND;VALUE=DATE:20241227
+RRULE:FREQ=YEARLY
+END:VEVENT
+END:VCALENDAR`), berlin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		location  *time.Location
+		windows   string
+		blackouts []Blackout
+		now       time.Time
+		duration  time.Duration
+		want      time.Duration
+	}{
+		{
+			name:     "within window",
+			location: berlin,
+			windows:  "mon-fri 08:00-18:00",
+			// friday, 10:00 in berlin
+			now:      time.Date(2025, 5, 30, 8, 0, 0, 0, time.UTC),
+			duration: time.Hour,
+			want:     time.Hour,
+		},
+		{
+			name:     "after window on friday is deferred to monday",
+			location: berlin,
+			windows:  "mon-fri 08:00-18:00",
+			// friday, 17:30 in berlin
+			now:      time.Date(2025, 5, 30, 15, 30, 0, 0, time.UTC),
+			duration: time.Hour,
+			want:     62*time.Hour + 30*time.Minute,
+		},
+		{
+			name:     "overnight window after midnight",
+			location: berlin,
+			windows:  "22:00-06:00",
+			// 02:00 in berlin
+			now:      time.Date(2025, 5, 30, 0, 0, 0, 0, time.UTC),
+			duration: time.Hour,
+			want:     time.Hour,
+		},
+		{
+			name:     "overnight window deferred to the evening",
+			location: berlin,
+			windows:  "22:00-06:00",
+			// 05:30 in berlin
+			now:      time.Date(2025, 5, 30, 3, 30, 0, 0, time.UTC),
+			duration: time.Hour,
+			want:     16*time.Hour + 30*time.Minute,
+		},
+		{
+			name:     "several windows per day",
+			location: time.UTC,
+			windows:  "06:00-08:00;18:00-20:00",
+			now:      time.Date(2025, 5, 30, 7, 30, 0, 0, time.UTC),
+			duration: time.Hour,
+			want:     10*time.Hour + 30*time.Minute,
+		},
+		{
+			name:      "blackout is skipped",
+			location:  berlin,
+			windows:   "08:00-18:00",
+			blackouts: christmas,
+			// december 24th, 17:30 in berlin
+			now:      time.Date(2025, 12, 24, 16, 30, 0, 0, time.UTC),
+			duration: time.Hour,
+			want:     62*time.Hour + 30*time.Minute,
+		},
+		{
+			name:      "blackout without windows",
+			location:  berlin,
+			blackouts: christmas,
+			now:       time.Date(2025, 12, 24, 22, 0, 0, 0, time.UTC),
+			duration:  time.Hour,
+			want:      49 * time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var windows []Window
+			if tt.windows != "" {
+				windows = mustParse(tt.windows)
+			}
+			c, err := NewCalendarRequeue(tt.location, windows, tt.blackouts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.timeSource = &fakeTime{ret: tt.now}
+
+			if got := c.Requeue(tt.duration); got != tt.want {
+				t.Errorf("Requeue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBlackouts(t *testing.T) {
+	ical := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:Change\r\n" +
+		" freeze\r\n" +
+		"DTSTART;TZID=Europe/Berlin:20251215T180000\r\n" +
+		"DTEND:20260105T060000Z\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:20250501\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART:20250601T100000\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	blackouts, err := ParseBlackouts(strings.NewReader(ical), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blackouts) != 2 {
+		t.Fatalf("expected 2 blackouts, got %d", len(blackouts))
+	}
+
+	if want := time.Date(2025, 12, 15, 17, 0, 0, 0, time.UTC); !blackouts[0].Start.Equal(want) {
+		t.Errorf("Start = %v, want %v", blackouts[0].Start, want)
+	}
+	if want := time.Date(2026, 1, 5, 6, 0, 0, 0, time.UTC); !blackouts[0].End.Equal(want) {
+		t.Errorf("End = %v, want %v", blackouts[0].End, want)
+	}
+	if want := time.Date(2025, 5, 2, 0, 0, 0, 0, time.UTC); !blackouts[1].End.Equal(want) {
+		t.Errorf("End = %v, want %v", blackouts[1].End, want)
+	}
+}
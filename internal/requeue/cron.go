@@ -0,0 +1,34 @@
+package requeue
+
+import (
+	"errors"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// CronSchedule is a parsed cron expression in the standard five field format "minute hour day-of-month month
+// day-of-week". Lists, ranges, steps, month and weekday names as well as the common macros such as "@daily" are
+// supported.
+type CronSchedule struct {
+	schedule cron.Schedule
+}
+
+// ParseCron parses the given cron expression.
+func ParseCron(expr string) (*CronSchedule, error) {
+	schedule, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &CronSchedule{schedule: schedule}, nil
+}
+
+// Next returns the first activation after the given time, evaluated in the location of the given time.
+func (c *CronSchedule) Next(after time.Time) (time.Time, error) {
+	// impossible expressions such as "0 0 30 2 *" yield the zero time
+	next := c.schedule.Next(after)
+	if next.IsZero() {
+		return time.Time{}, errors.New("cron expression has no activation in the foreseeable future")
+	}
+	return next, nil
+}
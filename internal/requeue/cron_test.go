@@ -0,0 +1,89 @@
+package requeue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronSchedule_Next(t *testing.T) {
+	// friday
+	now := time.Date(2025, 5, 30, 15, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		expr    string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name: "every 5 minutes",
+			expr: "*/5 * * * *",
+			want: time.Date(2025, 5, 30, 15, 10, 0, 0, time.UTC),
+		},
+		{
+			name: "daily macro",
+			expr: "@daily",
+			want: time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "working days at 9 and 17",
+			expr: "0 9,17 * * mon-fri",
+			want: time.Date(2025, 5, 30, 17, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "weekends only",
+			expr: "30 6 * * sat,sun",
+			want: time.Date(2025, 5, 31, 6, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "day of month or day of week",
+			expr: "0 0 1 * mon",
+			want: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "month names",
+			expr: "0 12 15 jan,jul *",
+			want: time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "never",
+			expr:    "0 0 30 2 *",
+			wantErr: true,
+		},
+		{
+			name:    "too few fields",
+			expr:    "* * * *",
+			wantErr: true,
+		},
+		{
+			name:    "out of range",
+			expr:    "60 * * * *",
+			wantErr: true,
+		},
+		{
+			name:    "sunday as 7",
+			expr:    "0 0 * * 7",
+			wantErr: true,
+		},
+		{
+			name:    "invalid step",
+			expr:    "*/0 * * * *",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseCron(tt.expr)
+			if err == nil {
+				var next time.Time
+				next, err = schedule.Next(now)
+				if err == nil && !next.Equal(tt.want) {
+					t.Errorf("Next() = %v, want %v", next, tt.want)
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package requeue
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Blackout is a period in which no requeue may happen.
+type Blackout struct {
+	Start time.Time
+	End   time.Time
+	// Yearly marks a blackout that repeats every year, e.g. a public holiday.
+	Yearly bool
+}
+
+// endIfContains returns the end of the blackout if the given time is within the blackout.
+func (b Blackout) endIfContains(t time.Time) (time.Time, bool) {
+	if !b.Yearly {
+		return b.End, !t.Before(b.Start) && t.Before(b.End)
+	}
+
+	// a yearly blackout may have started in the previous year if it spans the end of the year
+	for _, year := range []int{t.Year() - 1, t.Year()} {
+		yearsOffset := year - b.Start.Year()
+		start := b.Start.AddDate(yearsOffset, 0, 0)
+		end := b.End.AddDate(yearsOffset, 0, 0)
+		if !t.Before(start) && t.Before(end) {
+			return end, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ReadBlackoutsFromFile reads the events of an iCalendar file as blackouts.
+func ReadBlackoutsFromFile(path string, location *time.Location) ([]Blackout, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	return ParseBlackouts(file, location)
+}
+
+// ParseBlackouts parses the events of an iCalendar (RFC 5545) document as blackouts. Only DTSTART, DTEND and yearly
+// recurrence rules are evaluated. Dates without a time zone are interpreted in the given location.
+func ParseBlackouts(reader io.Reader, location *time.Location) ([]Blackout, error) {
+	if location == nil {
+		location = time.UTC
+	}
+
+	lines, err := unfoldICalLines(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []Blackout
+	var current *Blackout
+	var isAllDay bool
+	for _, line := range lines {
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		name, params, _ := strings.Cut(name, ";")
+
+		switch strings.ToUpper(name) {
+		case "BEGIN":
+			if strings.EqualFold(value, "VEVENT") {
+				current = &Blackout{}
+				isAllDay = false
+			}
+		case "END":
+			if !strings.EqualFold(value, "VEVENT") || current == nil {
+				continue
+			}
+			if current.Start.IsZero() {
+				return nil, fmt.Errorf("event without DTSTART")
+			}
+			if current.End.IsZero() {
+				// events without an end last for a single day if they are all-day events, otherwise they are empty
+				if !isAllDay {
+					current = nil
+					continue
+				}
+				current.End = current.Start.AddDate(0, 0, 1)
+			}
+			ret = append(ret, *current)
+			current = nil
+		case "DTSTART":
+			if current == nil {
+				continue
+			}
+			current.Start, isAllDay, err = parseICalTime(value, params, location)
+			if err != nil {
+				return nil, err
+			}
+		case "DTEND":
+			if current == nil {
+				continue
+			}
+			current.End, _, err = parseICalTime(value, params, location)
+			if err != nil {
+				return nil, err
+			}
+		case "RRULE":
+			if current != nil && strings.Contains(strings.ToUpper(value), "FREQ=YEARLY") {
+				current.Yearly = true
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// unfoldICalLines joins continuation lines, which start with a space or tab.
+func unfoldICalLines(reader io.Reader) ([]string, error) {
+	var ret []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(ret) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			ret[len(ret)-1] += line[1:]
+			continue
+		}
+		ret = append(ret, line)
+	}
+	return ret, scanner.Err()
+}
+
+func parseICalTime(value, params string, location *time.Location) (time.Time, bool, error) {
+	for _, param := range strings.Split(params, ";") {
+		key, paramValue, _ := strings.Cut(param, "=")
+		if strings.EqualFold(key, "TZID") {
+			tz, err := time.LoadLocation(paramValue)
+			if err != nil {
+				return time.Time{}, false, fmt.Errorf("unknown time zone %q: %w", paramValue, err)
+			}
+			location = tz
+		}
+	}
+
+	switch {
+	case len(value) == len("20060102"):
+		t, err := time.ParseInLocation("20060102", value, location)
+		return t, true, err
+	case strings.HasSuffix(value, "Z"):
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	default:
+		t, err := time.ParseInLocation("20060102T150405", value, location)
+		return t, false, err
+	}
+}
@@ -54,11 +54,11 @@ func NewOnHoursRequeue(from, to int) (*OnHoursRequeue, error) {
 func (w *OnHoursRequeue) Requeue(duration time.Duration) time.Duration {
 	currentTime := w.timeSource.Now().Add(duration)
 	if currentTime.Hour() < w.fromHour {
-		return time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), w.fromHour, 0, 0, 0, time.UTC).Sub(w.timeSource.Now())
+		return time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), w.fromHour, 0, 0, 0, currentTime.Location()).Sub(w.timeSource.Now())
 	}
 
 	if currentTime.Hour() >= w.toHour {
-		return (time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), w.fromHour, 0, 0, 0, time.UTC).AddDate(0, 0, 1)).Sub(w.timeSource.Now())
+		return (time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), w.fromHour, 0, 0, 0, currentTime.Location()).AddDate(0, 0, 1)).Sub(w.timeSource.Now())
 	}
 
 	return duration
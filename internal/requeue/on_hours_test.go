@@ -76,6 +76,18 @@ func TestOnHoursRequeue_Requeue(t *testing.T) {
 			},
 			want: 25 * time.Hour,
 		},
+		{
+			name: "before operating hours, non-UTC time",
+			fields: fields{
+				fromHour:   10,
+				toHour:     22,
+				timeSource: &fakeTime{ret: time.Date(2025, 05, 30, 9, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60))},
+			},
+			args: args{
+				duration: 30 * time.Minute,
+			},
+			want: 1 * time.Hour,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,94 @@
+package requeue
+
+import (
+	"errors"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// QuotaScheduler computes the polling interval for all repositories from their estimated request cost and the hourly
+// request budget, so polling never exceeds the quota. Each repository is assigned a fixed slot within the interval,
+// which spreads polling evenly.
+type QuotaScheduler struct {
+	hourlyBudget int
+	minInterval  time.Duration
+	timeSource   timeSource
+
+	mutex sync.Mutex
+	costs map[string]int
+}
+
+// NewQuotaScheduler creates a new QuotaScheduler.
+//
+// Parameters:
+//   - hourlyBudget: The amount of requests that may be sent per hour for polling all repositories.
+//   - minInterval: The lower bound of the computed interval.
+//
+// Returns:
+//   - A pointer to a QuotaScheduler.
+//   - An error if the budget or the minimum interval is not positive.
+func NewQuotaScheduler(hourlyBudget int, minInterval time.Duration) (*QuotaScheduler, error) {
+	if hourlyBudget <= 0 {
+		return nil, errors.New("hourly budget must be > 0")
+	}
+
+	if minInterval <= 0 {
+		return nil, errors.New("minimum interval must be > 0")
+	}
+
+	return &QuotaScheduler{
+		hourlyBudget: hourlyBudget,
+		minInterval:  minInterval,
+		timeSource:   &defaultTimeSource{},
+		costs:        map[string]int{},
+	}, nil
+}
+
+// Observe records the estimated amount of requests a single poll of the given repository costs.
+func (s *QuotaScheduler) Observe(key string, cost int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.costs[key] = max(cost, 1)
+}
+
+// Forget removes a repository that is not watched anymore.
+func (s *QuotaScheduler) Forget(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.costs, key)
+}
+
+// Interval returns the interval all repositories need to be polled in so the total cost per hour stays within the
+// budget, but not less than the configured minimum interval.
+func (s *QuotaScheduler) Interval() time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	totalCost := 0
+	for _, cost := range s.costs {
+		totalCost += cost
+	}
+
+	interval := time.Duration(float64(totalCost) / float64(s.hourlyBudget) * float64(time.Hour))
+	return max(interval, s.minInterval)
+}
+
+// NextPoll returns the duration until the next slot of the given repository. Slots are derived from a hash of the key,
+// so repositories are distributed evenly across the interval.
+func (s *QuotaScheduler) NextPoll(key string) time.Duration {
+	interval := s.Interval()
+	now := s.timeSource.Now()
+
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(key))
+	offset := time.Duration(hash.Sum64() % uint64(interval))
+
+	// position of now within the current interval, relative to the repository's slot
+	elapsed := time.Duration(now.UnixNano()-int64(offset)) % interval
+	if elapsed < 0 {
+		elapsed += interval
+	}
+
+	return interval - elapsed
+}
@@ -0,0 +1,88 @@
+package requeue
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestNewQuotaScheduler(t *testing.T) {
+	tests := []struct {
+		name         string
+		hourlyBudget int
+		minInterval  time.Duration
+		wantErr      bool
+	}{
+		{name: "valid", hourlyBudget: 5000, minInterval: time.Minute},
+		{name: "no budget", hourlyBudget: 0, minInterval: time.Minute, wantErr: true},
+		{name: "no minimum interval", hourlyBudget: 5000, minInterval: 0, wantErr: true},
+		{name: "negative minimum interval", hourlyBudget: 5000, minInterval: -time.Minute, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewQuotaScheduler(tt.hourlyBudget, tt.minInterval)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewQuotaScheduler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQuotaScheduler_Interval(t *testing.T) {
+	scheduler, err := NewQuotaScheduler(5000, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := scheduler.Interval(); got != time.Hour {
+		t.Errorf("Interval() = %v, want minimum interval", got)
+	}
+
+	// 300 repositories costing 25 requests each need 1.5 hours to stay within the budget
+	for i := 0; i < 300; i++ {
+		scheduler.Observe(fmt.Sprintf("repo-%d", i), 25)
+	}
+	if got := scheduler.Interval(); got != 90*time.Minute {
+		t.Errorf("Interval() = %v, want %v", got, 90*time.Minute)
+	}
+
+	for i := 0; i < 150; i++ {
+		scheduler.Forget(fmt.Sprintf("repo-%d", i))
+	}
+	if got := scheduler.Interval(); got != time.Hour {
+		t.Errorf("Interval() = %v, want minimum interval", got)
+	}
+}
+
+func TestQuotaScheduler_NextPoll(t *testing.T) {
+	scheduler, err := NewQuotaScheduler(5000, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2025, 5, 30, 15, 0, 0, 0, time.UTC)
+	clock := &fakeTime{ret: now}
+	scheduler.timeSource = clock
+
+	first := scheduler.NextPoll("namespace/repo")
+	if first <= 0 || first > time.Hour {
+		t.Fatalf("NextPoll() = %v, expected to be within the interval", first)
+	}
+
+	// after polling in the slot, the next slot is exactly one interval later
+	clock.ret = now.Add(first)
+	if got := scheduler.NextPoll("namespace/repo"); got != time.Hour {
+		t.Errorf("NextPoll() = %v, want %v", got, time.Hour)
+	}
+
+	// slots are spread across the interval
+	slots := map[time.Duration]bool{}
+	clock.ret = now
+	for i := 0; i < 100; i++ {
+		slots[scheduler.NextPoll(fmt.Sprintf("namespace/repo-%d", i)).Truncate(10*time.Minute)] = true
+	}
+	if len(slots) < 6 {
+		t.Errorf("expected slots to be spread across the interval, got %d distinct buckets", len(slots))
+	}
+}
@@ -0,0 +1,100 @@
+package tekton
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// DeclarationsOfTask returns a PipelineSpec declaring the params and workspaces of the task, so TaskRuns are checked
+// for compatibility the same way as PipelineRuns.
+func DeclarationsOfTask(spec pipelinev1.TaskSpec) *pipelinev1.PipelineSpec {
+	ret := &pipelinev1.PipelineSpec{Params: spec.Params}
+	for _, workspace := range spec.Workspaces {
+		ret.Workspaces = append(ret.Workspaces, pipelinev1.PipelineWorkspaceDeclaration{
+			Name:     workspace.Name,
+			Optional: workspace.Optional,
+		})
+	}
+	return ret
+}
+
+// ParsePipelineSpec parses an inline pipelineSpec in YAML or JSON. Unknown fields are rejected.
+func ParsePipelineSpec(data string) (*pipelinev1.PipelineSpec, error) {
+	spec := &pipelinev1.PipelineSpec{}
+	if err := yaml.UnmarshalStrict([]byte(data), spec); err != nil {
+		return nil, err
+	}
+
+	if len(spec.Tasks) == 0 {
+		return nil, errors.New("pipelineSpec declares no tasks")
+	}
+	return spec, nil
+}
+
+// CheckCompatibility returns the mismatches between the params and workspaces of the request and the ones declared
+// by the pipeline that would make Tekton reject the PipelineRun. Params the pipeline does not declare are no
+// mismatch, as they are dropped by DropUndeclaredParams.
+func CheckCompatibility(req CreatePipelineRunRequest, spec pipelinev1.PipelineSpec) []string {
+	var mismatches []string
+
+	sentParams := map[string]pipelinev1.ParamType{}
+	for _, param := range getParams(req) {
+		sentParams[param.Name] = param.Value.Type
+	}
+
+	for _, declared := range spec.Params {
+		sentType, found := sentParams[declared.Name]
+		if !found {
+			if declared.Default == nil {
+				mismatches = append(mismatches, fmt.Sprintf("param %q is required", declared.Name))
+			}
+			continue
+		}
+
+		declaredType := cmp.Or(declared.Type, pipelinev1.ParamTypeString)
+		if sentType != declaredType {
+			mismatches = append(mismatches, fmt.Sprintf("param %q is of type %s, pipeline declares %s", declared.Name, sentType, declaredType))
+		}
+	}
+
+	for _, declared := range spec.Workspaces {
+		if _, found := req.WorkspaceBindings[declared.Name]; !found && !declared.Optional {
+			mismatches = append(mismatches, fmt.Sprintf("workspace %q is required", declared.Name))
+		}
+	}
+
+	return mismatches
+}
+
+// DropUndeclaredParams removes the params the pipeline does not declare from the request and returns their sorted
+// names.
+func DropUndeclaredParams(req *CreatePipelineRunRequest, spec pipelinev1.PipelineSpec) []string {
+	isDeclared := func(name string) bool {
+		return slices.ContainsFunc(spec.Params, func(p pipelinev1.ParamSpec) bool { return p.Name == name })
+	}
+
+	var dropped []string
+	for _, name := range slices.Sorted(maps.Keys(req.Params)) {
+		if !isDeclared(name) {
+			dropped = append(dropped, name)
+			delete(req.Params, name)
+		}
+	}
+
+	req.RenderedParams = slices.DeleteFunc(req.RenderedParams, func(p pipelinev1.Param) bool {
+		if isDeclared(p.Name) {
+			return false
+		}
+		dropped = append(dropped, p.Name)
+		return true
+	})
+
+	slices.Sort(dropped)
+	return slices.Compact(dropped)
+}
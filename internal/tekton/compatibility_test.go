@@ -0,0 +1,128 @@
+package tekton
+
+import (
+	"reflect"
+	"testing"
+
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	req := CreatePipelineRunRequest{
+		Params: map[string]string{ArgCloneUrl: "https://github.com/owner/repo.git", ArgRevision: "v1.0.0"},
+		RenderedParams: []pipelinev1.Param{
+			{Name: "platforms", Value: *pipelinev1.NewStructuredValues("linux/amd64", "linux/arm64")},
+		},
+		WorkspaceBindings: map[string]map[string]string{"shared-data": {"type": "volume"}},
+	}
+
+	tests := []struct {
+		name string
+		spec pipelinev1.PipelineSpec
+		want []string
+	}{
+		{
+			name: "compatible",
+			spec: pipelinev1.PipelineSpec{
+				Params: pipelinev1.ParamSpecs{
+					{Name: ArgCloneUrl},
+					{Name: "platforms", Type: pipelinev1.ParamTypeArray},
+					{Name: "dry-run", Default: pipelinev1.NewStructuredValues("false")},
+				},
+				Workspaces: []pipelinev1.PipelineWorkspaceDeclaration{
+					{Name: "shared-data"},
+					{Name: "cache", Optional: true},
+				},
+			},
+		},
+		{
+			name: "mismatches",
+			spec: pipelinev1.PipelineSpec{
+				Params: pipelinev1.ParamSpecs{
+					{Name: "image"},
+					{Name: "platforms", Type: pipelinev1.ParamTypeString},
+				},
+				Workspaces: []pipelinev1.PipelineWorkspaceDeclaration{{Name: "signify"}},
+			},
+			want: []string{
+				`param "image" is required`,
+				`param "platforms" is of type array, pipeline declares string`,
+				`workspace "signify" is required`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckCompatibility(req, tt.spec); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckCompatibility() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDropUndeclaredParams(t *testing.T) {
+	req := &CreatePipelineRunRequest{
+		Params: map[string]string{ArgCloneUrl: "https://github.com/owner/repo.git", ArgRevision: "v1.0.0", ArgOwner: "owner"},
+		RenderedParams: []pipelinev1.Param{
+			{Name: "version", Value: *pipelinev1.NewStructuredValues("1.0.0")},
+			{Name: "commit", Value: *pipelinev1.NewStructuredValues("abc123")},
+		},
+	}
+	spec := pipelinev1.PipelineSpec{Params: pipelinev1.ParamSpecs{{Name: ArgCloneUrl}, {Name: ArgRevision}, {Name: "version"}}}
+
+	dropped := DropUndeclaredParams(req, spec)
+	if want := []string{"commit", ArgOwner}; !reflect.DeepEqual(dropped, want) {
+		t.Errorf("DropUndeclaredParams() got = %v, want %v", dropped, want)
+	}
+
+	var names []string
+	for _, param := range getParams(*req) {
+		names = append(names, param.Name)
+	}
+	if len(names) != 3 {
+		t.Errorf("DropUndeclaredParams() left params %v", names)
+	}
+}
+
+func TestParsePipelineSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			data: `
+params:
+  - name: revision
+tasks:
+  - name: build
+    taskRef:
+      name: golang-build
+`,
+		},
+		{
+			name:    "no tasks",
+			data:    "params: []",
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			data:    "taks: []",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePipelineSpec(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePipelineSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (len(got.Tasks) != 1 || got.Tasks[0].TaskRef.Name != "golang-build") {
+				t.Errorf("ParsePipelineSpec() got = %v", got)
+			}
+		})
+	}
+}
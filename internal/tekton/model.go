@@ -1,5 +1,10 @@
 package tekton
 
+import (
+	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
 type CreatePipelineRunRequest struct {
 	Namespace       string
 	PipelineRunName string
@@ -7,4 +12,14 @@ type CreatePipelineRunRequest struct {
 
 	Params            map[string]string
 	WorkspaceBindings map[string]map[string]string
+	Template          *gollumv1alpha1.PipelineRunTemplate
+	Resolver          *gollumv1alpha1.PipelineResolver
+
+	// Target is the kind of the resource PipelineName refers to.
+	Target gollumv1alpha1.BuildTarget
+	// PipelineSpec is embedded into the PipelineRun instead of referencing a Pipeline by name if set.
+	PipelineSpec *pipelinev1.PipelineSpec
+
+	// RenderedParams are the params of the Repository, rendered using the data of the release.
+	RenderedParams []pipelinev1.Param
 }
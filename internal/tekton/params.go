@@ -0,0 +1,179 @@
+package tekton
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/semver/v3"
+	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+	"github.com/soerenschneider/gollum/internal/github"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+var templateFuncs = template.FuncMap{
+	"join":       strings.Join,
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"replace":    strings.ReplaceAll,
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+}
+
+// Semver are the parts of a tag that is a semantic version. All fields are empty if the tag is no semantic version.
+type Semver struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease string
+	Metadata   string
+}
+
+// ReleaseData is the data the templates of params are rendered with.
+type ReleaseData struct {
+	Owner      string
+	Repository string
+	Tag        string
+	// Version is the tag without a leading "v".
+	Version          string
+	Semver           Semver
+	Prerelease       bool
+	ReleaseID        int64
+	UploadURL        string
+	MissingArtifacts []string
+
+	resolveCommitSHA func() (string, error)
+	commitSHA        string
+}
+
+// NewReleaseData returns the data of a release. The commit SHA is only resolved using resolveCommitSHA if a template
+// refers to it.
+func NewReleaseData(owner, repo string, release github.Release, missingArtifacts []string, resolveCommitSHA func() (string, error)) *ReleaseData {
+	data := &ReleaseData{
+		Owner:            owner,
+		Repository:       repo,
+		Tag:              release.TagName,
+		Version:          strings.TrimPrefix(release.TagName, "v"),
+		Prerelease:       release.Prerelease,
+		ReleaseID:        release.ID,
+		UploadURL:        release.UploadURL,
+		MissingArtifacts: missingArtifacts,
+		resolveCommitSHA: resolveCommitSHA,
+	}
+
+	if version, err := semver.NewVersion(release.TagName); err == nil {
+		data.Semver = Semver{
+			Major:      version.Major(),
+			Minor:      version.Minor(),
+			Patch:      version.Patch(),
+			Prerelease: version.Prerelease(),
+			Metadata:   version.Metadata(),
+		}
+	}
+
+	return data
+}
+
+// CommitSHA returns the SHA of the commit the tag points to.
+func (d *ReleaseData) CommitSHA() (string, error) {
+	if d.commitSHA != "" {
+		return d.commitSHA, nil
+	}
+
+	if d.resolveCommitSHA == nil {
+		return "", errors.New("resolving the commit of a tag is not supported")
+	}
+
+	sha, err := d.resolveCommitSHA()
+	if err != nil {
+		return "", fmt.Errorf("could not resolve commit of tag %q: %w", d.Tag, err)
+	}
+
+	d.commitSHA = sha
+	return sha, nil
+}
+
+// ValidateParams checks that the names of the params are unique and that their templates can be rendered. Templates
+// are rendered using the data of a sample release, so references to unknown fields are detected.
+func ValidateParams(params []gollumv1alpha1.Param) error {
+	names := make(map[string]bool, len(params))
+	for _, param := range params {
+		if names[param.Name] {
+			return fmt.Errorf("param %q: duplicate name", param.Name)
+		}
+		names[param.Name] = true
+	}
+
+	_, err := RenderParams(params, sampleReleaseData())
+	return err
+}
+
+// sampleReleaseData returns the data of a fictional release that is used to validate templates.
+func sampleReleaseData() *ReleaseData {
+	release := github.Release{
+		ID:        1,
+		TagName:   "v1.2.3",
+		UploadURL: "https://uploads.github.com/repos/owner/repo/releases/1/assets{?name,label}",
+	}
+	resolveCommitSHA := func() (string, error) {
+		return "0000000000000000000000000000000000000000", nil
+	}
+	missingArtifacts := []string{string(gollumv1alpha1.ArtifactsKeyReleaseAssets), string(gollumv1alpha1.ArtifactsKeyPackagesContainer)}
+	return NewReleaseData("owner", "repo", release, missingArtifacts, resolveCommitSHA)
+}
+
+// RenderParams renders the templates of the params using the data of the release.
+func RenderParams(params []gollumv1alpha1.Param, data *ReleaseData) ([]pipelinev1.Param, error) {
+	ret := make([]pipelinev1.Param, 0, len(params))
+
+	for _, param := range params {
+		value := pipelinev1.ParamValue{}
+		var err error
+
+		switch param.Type {
+		case gollumv1alpha1.ParamTypeString, "":
+			value.Type = pipelinev1.ParamTypeString
+			value.StringVal, err = render(param.Value, data)
+		case gollumv1alpha1.ParamTypeArray:
+			value.Type = pipelinev1.ParamTypeArray
+			value.ArrayVal = make([]string, len(param.Values))
+			for idx, val := range param.Values {
+				if value.ArrayVal[idx], err = render(val, data); err != nil {
+					break
+				}
+			}
+		case gollumv1alpha1.ParamTypeObject:
+			value.Type = pipelinev1.ParamTypeObject
+			value.ObjectVal = make(map[string]string, len(param.Properties))
+			for key, val := range param.Properties {
+				if value.ObjectVal[key], err = render(val, data); err != nil {
+					break
+				}
+			}
+		default:
+			err = fmt.Errorf("unknown type %q", param.Type)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("param %q: %w", param.Name, err)
+		}
+
+		ret = append(ret, pipelinev1.Param{Name: param.Name, Value: value})
+	}
+
+	return ret, nil
+}
+
+func render(text string, data *ReleaseData) (string, error) {
+	tmpl, err := template.New("").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,144 @@
+package tekton
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+	"github.com/soerenschneider/gollum/internal/github"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+func TestRenderParams(t *testing.T) {
+	release := github.Release{
+		ID:         42,
+		TagName:    "v1.2.3-rc.1+build.5",
+		Prerelease: true,
+		UploadURL:  "https://uploads.github.com/repos/owner/repo/releases/42/assets{?name,label}",
+	}
+
+	tests := []struct {
+		name       string
+		params     []gollumv1alpha1.Param
+		resolveSHA func() (string, error)
+		want       []pipelinev1.Param
+		wantErr    bool
+	}{
+		{
+			name: "string params",
+			params: []gollumv1alpha1.Param{
+				{Name: "version", Value: "{{ .Version }}"},
+				{Name: "major-minor", Type: gollumv1alpha1.ParamTypeString, Value: "{{ .Semver.Major }}.{{ .Semver.Minor }}"},
+				{Name: "prerelease", Value: "{{ .Prerelease }}-{{ .Semver.Prerelease }}"},
+				{Name: "id", Value: "{{ .ReleaseID }}"},
+				{Name: "missing", Value: `{{ join .MissingArtifacts "," }}`},
+			},
+			want: []pipelinev1.Param{
+				{Name: "version", Value: *pipelinev1.NewStructuredValues("1.2.3-rc.1+build.5")},
+				{Name: "major-minor", Value: *pipelinev1.NewStructuredValues("1.2")},
+				{Name: "prerelease", Value: *pipelinev1.NewStructuredValues("true-rc.1")},
+				{Name: "id", Value: *pipelinev1.NewStructuredValues("42")},
+				{Name: "missing", Value: *pipelinev1.NewStructuredValues("assets,packages")},
+			},
+		},
+		{
+			name: "array and object params",
+			params: []gollumv1alpha1.Param{
+				{Name: "tags", Type: gollumv1alpha1.ParamTypeArray, Values: []string{"{{ .Tag }}", "latest"}},
+				{Name: "release", Type: gollumv1alpha1.ParamTypeObject, Properties: map[string]string{"owner": "{{ .Owner }}", "repo": "{{ .Repository }}"}},
+			},
+			want: []pipelinev1.Param{
+				{Name: "tags", Value: *pipelinev1.NewStructuredValues("v1.2.3-rc.1+build.5", "latest")},
+				{Name: "release", Value: *pipelinev1.NewObject(map[string]string{"owner": "owner", "repo": "repo"})},
+			},
+		},
+		{
+			name:       "commit sha",
+			params:     []gollumv1alpha1.Param{{Name: "sha", Value: "{{ .CommitSHA }}"}},
+			resolveSHA: func() (string, error) { return "abc123", nil },
+			want:       []pipelinev1.Param{{Name: "sha", Value: *pipelinev1.NewStructuredValues("abc123")}},
+		},
+		{
+			name:       "commit sha not resolvable",
+			params:     []gollumv1alpha1.Param{{Name: "sha", Value: "{{ .CommitSHA }}"}},
+			resolveSHA: func() (string, error) { return "", errors.New("not found") },
+			wantErr:    true,
+		},
+		{
+			name:    "unknown field",
+			params:  []gollumv1alpha1.Param{{Name: "version", Value: "{{ .Unknown }}"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid template",
+			params:  []gollumv1alpha1.Param{{Name: "version", Value: "{{ .Version"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := NewReleaseData("owner", "repo", release, []string{"assets", "packages"}, tt.resolveSHA)
+			got, err := RenderParams(tt.params, data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RenderParams() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  []gollumv1alpha1.Param
+		wantErr bool
+	}{
+		{
+			name: "valid params",
+			params: []gollumv1alpha1.Param{
+				{Name: "version", Value: "{{ .Version }}"},
+				{Name: "sha", Value: "{{ .CommitSHA }}"},
+				{Name: "tags", Type: gollumv1alpha1.ParamTypeArray, Values: []string{"{{ .Tag }}", `{{ index .MissingArtifacts 0 }}`}},
+				{Name: "release", Type: gollumv1alpha1.ParamTypeObject, Properties: map[string]string{"major": "{{ .Semver.Major }}"}},
+			},
+		},
+		{
+			name: "no params",
+		},
+		{
+			name:    "unknown field",
+			params:  []gollumv1alpha1.Param{{Name: "version", Value: "{{ .Unknown }}"}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown field in array",
+			params:  []gollumv1alpha1.Param{{Name: "tags", Type: gollumv1alpha1.ParamTypeArray, Values: []string{"{{ .Semver.Unknown }}"}}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid template",
+			params:  []gollumv1alpha1.Param{{Name: "version", Value: "{{ .Version"}},
+			wantErr: true,
+		},
+		{
+			name: "duplicate name",
+			params: []gollumv1alpha1.Param{
+				{Name: "version", Value: "{{ .Version }}"},
+				{Name: "version", Value: "{{ .Tag }}"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateParams(tt.params); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
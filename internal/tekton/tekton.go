@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 
+	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -12,11 +14,25 @@ import (
 )
 
 const (
-	valSecret           = "secret"
-	keyType             = "type"
-	valVolume           = "volume"
-	keyStorageClassName = "storageClassName"
-	keySecretName       = "secretName"
+	keyType                  = gollumv1alpha1.WorkspaceKeyType
+	keySecretName            = gollumv1alpha1.WorkspaceKeySecretName
+	keyConfigMapName         = gollumv1alpha1.WorkspaceKeyConfigMapName
+	keyClaimName             = gollumv1alpha1.WorkspaceKeyClaimName
+	keyStorageClassName      = gollumv1alpha1.WorkspaceKeyStorageClassName
+	keySize                  = gollumv1alpha1.WorkspaceKeySize
+	keyAccessModes           = gollumv1alpha1.WorkspaceKeyAccessModes
+	keyItems                 = gollumv1alpha1.WorkspaceKeyItems
+	keySources               = gollumv1alpha1.WorkspaceKeySources
+	keyDriver                = gollumv1alpha1.WorkspaceKeyDriver
+	keyReadOnly              = gollumv1alpha1.WorkspaceKeyReadOnly
+	keyVolumeAttributes      = gollumv1alpha1.WorkspaceKeyVolumeAttributes
+	valSecret                = gollumv1alpha1.WorkspaceTypeSecret
+	valConfigMap             = gollumv1alpha1.WorkspaceTypeConfigMap
+	valEmptyDir              = gollumv1alpha1.WorkspaceTypeEmptyDir
+	valPersistentVolumeClaim = gollumv1alpha1.WorkspaceTypePersistentVolumeClaim
+	valVolume                = gollumv1alpha1.WorkspaceTypeVolume
+	valProjected             = gollumv1alpha1.WorkspaceTypeProjected
+	valCSI                   = gollumv1alpha1.WorkspaceTypeCSI
 )
 
 var (
@@ -24,6 +40,10 @@ var (
 	ErrTektonPipelineNotFound                 = errors.New("tekton pipeline not found")
 	ErrTektonGetPipelineForbidden             = errors.New("forbidden to get tekton pipeline")
 	ErrTektonInvalidPipelineRunSpec           = errors.New("can not build PipelineRun from gollum spec")
+	ErrTektonCreateTaskRunUnauthorized        = errors.New("unauthorized to create tekton taskrun resource")
+	ErrTektonTaskNotFound                     = errors.New("tekton task not found")
+	ErrTektonGetTaskForbidden                 = errors.New("forbidden to get tekton task")
+	ErrTektonInvalidTaskRunSpec               = errors.New("can not build TaskRun from gollum spec")
 )
 
 type TektonPipelineRunner struct {
@@ -85,6 +105,10 @@ func (t *TektonPipelineRunner) CreatePipelineRun(ctx context.Context, req Create
 		},
 		Spec: *pipelineSpec,
 	}
+	if req.Template != nil {
+		pipelineRun.Labels = maps.Clone(req.Template.Metadata.Labels)
+		pipelineRun.Annotations = maps.Clone(req.Template.Metadata.Annotations)
+	}
 
 	run, err := t.client.TektonV1().PipelineRuns(req.Namespace).Create(ctx, pipelineRun, metav1.CreateOptions{})
 	if err != nil {
@@ -95,3 +119,65 @@ func (t *TektonPipelineRunner) CreatePipelineRun(ctx context.Context, req Create
 
 	return run, err
 }
+
+func (t *TektonPipelineRunner) GetTask(ctx context.Context, namespace, name string) (*pipelinev1.Task, error) {
+	task, err := t.client.TektonV1().Tasks(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		return task, nil
+	}
+
+	if k8serrors.IsNotFound(err) {
+		return nil, ErrTektonTaskNotFound
+	}
+
+	if k8serrors.IsForbidden(err) {
+		return nil, ErrTektonGetTaskForbidden
+	}
+
+	return nil, err
+}
+
+func (t *TektonPipelineRunner) GetTaskRun(ctx context.Context, namespace, name string) (*pipelinev1.TaskRun, error) {
+	run, err := t.client.TektonV1().TaskRuns(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		return run, nil
+	}
+
+	if k8serrors.IsNotFound(err) {
+		return nil, ErrTektonTaskNotFound
+	}
+
+	if k8serrors.IsForbidden(err) {
+		return nil, ErrTektonGetTaskForbidden
+	}
+
+	return nil, err
+}
+
+func (t *TektonPipelineRunner) CreateTaskRun(ctx context.Context, req CreatePipelineRunRequest) (*pipelinev1.TaskRun, error) {
+	taskRunSpec, err := getTaskRunSpec(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w (%w)", ErrTektonInvalidTaskRunSpec, err)
+	}
+
+	taskRun := &pipelinev1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    req.Namespace,
+			GenerateName: fmt.Sprintf("%s-", req.PipelineRunName),
+		},
+		Spec: *taskRunSpec,
+	}
+	if req.Template != nil {
+		taskRun.Labels = maps.Clone(req.Template.Metadata.Labels)
+		taskRun.Annotations = maps.Clone(req.Template.Metadata.Annotations)
+	}
+
+	run, err := t.client.TektonV1().TaskRuns(req.Namespace).Create(ctx, taskRun, metav1.CreateOptions{})
+	if err != nil {
+		if k8serrors.IsForbidden(err) {
+			return nil, ErrTektonCreateTaskRunUnauthorized
+		}
+	}
+
+	return run, err
+}
@@ -2,14 +2,17 @@ package tekton
 
 import (
 	"cmp"
+	"errors"
 	"fmt"
+	"maps"
+	"net/url"
+	"slices"
 	"strings"
 
-	"github.com/hashicorp/go-multierror"
 	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/pod"
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
-	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
 )
 
 const (
@@ -19,6 +22,13 @@ const (
 	ArgRepo             = "repository"
 	ArgRevision         = "revision"
 	DefaultRevision     = ""
+	DefaultCloneBaseUrl = "https://github.com"
+
+	ResolverGit             = "git"
+	ResolverBundles         = "bundles"
+	resolverParamName       = "name"
+	resolverParamPathInRepo = "pathInRepo"
+	resolverParamKind       = "kind"
 )
 
 func BuildRunRequest(tag string, namespace string, data *gollumv1alpha1.Repository, artifactType gollumv1alpha1.ArtifactType) *CreatePipelineRunRequest {
@@ -33,12 +43,15 @@ func BuildRunRequest(tag string, namespace string, data *gollumv1alpha1.Reposito
 		PipelineRunName: pipelineRunName,
 		PipelineName:    pipelineName,
 		Params: map[string]string{
-			ArgCloneUrl: GetRepoUrl(data.Spec.CloneUsingSsh, data.Spec.Owner, data.Spec.Repository),
+			ArgCloneUrl: GetRepoUrl(ptr.Deref(data.Spec.CloneUsingSsh, false), getCloneBaseUrl(data.Spec.Provider), data.Spec.Owner, data.Spec.Repository),
 			ArgRevision: tag,
 			ArgOwner:    data.Spec.Owner,
 			ArgRepo:     data.Spec.Repository,
 		},
 		WorkspaceBindings: data.Spec.Workspaces,
+		Template:          data.Spec.PipelineRunTemplate,
+		Resolver:          data.Spec.PipelineResolver,
+		Target:            cmp.Or(data.Spec.BuildTarget, gollumv1alpha1.BuildTargetPipeline),
 	}
 }
 
@@ -49,11 +62,23 @@ func safeSlice(s string, n int) string {
 	return s[:n]
 }
 
-func GetRepoUrl(sshCheckout bool, owner, repo string) string {
+func GetRepoUrl(sshCheckout bool, baseUrl, owner, repo string) string {
+	parsed, err := url.Parse(baseUrl)
+	if err != nil || parsed.Host == "" {
+		parsed, _ = url.Parse(DefaultCloneBaseUrl)
+	}
+
 	if sshCheckout {
-		return fmt.Sprintf("git@github.com:%s/%s.git", owner, repo)
+		return fmt.Sprintf("git@%s:%s/%s.git", parsed.Hostname(), owner, repo)
 	}
-	return fmt.Sprintf("https://github.com/%s/%s.git", owner, repo)
+	return fmt.Sprintf("%s://%s%s/%s/%s.git", parsed.Scheme, parsed.Host, strings.TrimSuffix(parsed.Path, "/"), owner, repo)
+}
+
+func getCloneBaseUrl(provider *gollumv1alpha1.ProviderSpec) string {
+	if provider == nil || provider.Type != gollumv1alpha1.ProviderGitea || provider.URL == "" {
+		return DefaultCloneBaseUrl
+	}
+	return provider.URL
 }
 
 func getPipelineRunSpec(req CreatePipelineRunRequest) (*pipelinev1.PipelineRunSpec, error) {
@@ -62,67 +87,183 @@ func getPipelineRunSpec(req CreatePipelineRunRequest) (*pipelinev1.PipelineRunSp
 		return nil, fmt.Errorf("could not build workspaces spec: %w", err)
 	}
 
-	return &pipelinev1.PipelineRunSpec{
-		PipelineRef: &pipelinev1.PipelineRef{
-			Name: req.PipelineName,
-		},
+	spec := &pipelinev1.PipelineRunSpec{
 		Workspaces: workspaces,
 		Params:     getParams(req),
-	}, nil
+	}
+	if req.PipelineSpec != nil {
+		spec.PipelineSpec = req.PipelineSpec.DeepCopy()
+	} else {
+		spec.PipelineRef = getPipelineRef(req)
+	}
+	applyPipelineRunTemplate(spec, req.Template)
+	return spec, nil
 }
 
-func getWorkspaceBindings(req CreatePipelineRunRequest) ([]pipelinev1.WorkspaceBinding, error) {
-	ret := make([]pipelinev1.WorkspaceBinding, 0, len(req.WorkspaceBindings))
-	var errs error
+func getTaskRunSpec(req CreatePipelineRunRequest) (*pipelinev1.TaskRunSpec, error) {
+	workspaces, err := getWorkspaceBindings(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not build workspaces spec: %w", err)
+	}
 
-	for key, val := range req.WorkspaceBindings {
-		binding := pipelinev1.WorkspaceBinding{
-			Name: key,
+	spec := &pipelinev1.TaskRunSpec{
+		TaskRef:    &pipelinev1.TaskRef{Name: req.PipelineName},
+		Workspaces: workspaces,
+		Params:     getParams(req),
+	}
+	if req.Resolver != nil {
+		spec.TaskRef = &pipelinev1.TaskRef{ResolverRef: getResolverRef(req)}
+	}
+	applyTaskRunTemplate(spec, req.Template)
+	return spec, nil
+}
+
+// ValidatePipelineResolver checks that the params required to locate the pipeline are set for the git and bundles
+// resolvers, and that the build target can be resolved remotely.
+func ValidatePipelineResolver(resolver *gollumv1alpha1.PipelineResolver, target gollumv1alpha1.BuildTarget) error {
+	if resolver == nil {
+		return nil
+	}
+
+	if target == gollumv1alpha1.BuildTargetPipelineSpec {
+		return errors.New("inline pipeline specs can not be resolved remotely")
+	}
+
+	if kind, found := resolver.Params[resolverParamKind]; found && resolver.Resolver != ResolverGit && kind != getResolverKind(target) {
+		return fmt.Errorf("param %q of the resolver must be %q for build target %q", resolverParamKind, getResolverKind(target), cmp.Or(target, gollumv1alpha1.BuildTargetPipeline))
+	}
+
+	switch resolver.Resolver {
+	case ResolverGit:
+		if resolver.Params["url"] == "" && resolver.Params["repo"] == "" {
+			return errors.New("git resolver requires either param \"url\" or \"repo\"")
+		}
+	case ResolverBundles:
+		if resolver.Params["bundle"] == "" {
+			return errors.New("bundles resolver requires param \"bundle\"")
+		}
+	}
+
+	return nil
+}
+
+// getPipelineRef references the pipeline by name or, if a resolver is configured, using the remote resolver.
+func getPipelineRef(req CreatePipelineRunRequest) *pipelinev1.PipelineRef {
+	if req.Resolver == nil {
+		return &pipelinev1.PipelineRef{Name: req.PipelineName}
+	}
+	return &pipelinev1.PipelineRef{ResolverRef: getResolverRef(req)}
+}
+
+// getResolverKind returns the kind of the resource that is resolved for the build target.
+func getResolverKind(target gollumv1alpha1.BuildTarget) string {
+	if target == gollumv1alpha1.BuildTargetTask {
+		return "task"
+	}
+	return "pipeline"
+}
+
+// getResolverRef passes the params of the resolver and the pipeline name, unless its param is set explicitly. All
+// resolvers but git look up the resource by kind, which defaults to the kind of the build target.
+func getResolverRef(req CreatePipelineRunRequest) pipelinev1.ResolverRef {
+	params := maps.Clone(req.Resolver.Params)
+	if params == nil {
+		params = map[string]string{}
+	}
+
+	nameParam := resolverParamName
+	if req.Resolver.Resolver == ResolverGit {
+		nameParam = resolverParamPathInRepo
+	}
+	if _, found := params[nameParam]; !found {
+		params[nameParam] = req.PipelineName
+	}
+	if _, found := params[resolverParamKind]; !found && req.Resolver.Resolver != ResolverGit {
+		params[resolverParamKind] = getResolverKind(req.Target)
+	}
+
+	ref := pipelinev1.ResolverRef{
+		Resolver: pipelinev1.ResolverName(req.Resolver.Resolver),
+	}
+	for _, key := range slices.Sorted(maps.Keys(params)) {
+		ref.Params = append(ref.Params, pipelinev1.Param{
+			Name:  key,
+			Value: *pipelinev1.NewStructuredValues(params[key]),
+		})
+	}
+
+	return ref
+}
+
+// applyPipelineRunTemplate merges the template of the Repository into the spec of the PipelineRun.
+func applyPipelineRunTemplate(spec *pipelinev1.PipelineRunSpec, template *gollumv1alpha1.PipelineRunTemplate) {
+	if template == nil {
+		return
+	}
+
+	if template.TaskRunTemplate != nil {
+		spec.TaskRunTemplate = pipelinev1.PipelineTaskRunTemplate{
+			ServiceAccountName: template.TaskRunTemplate.ServiceAccountName,
+			PodTemplate:        getPodTemplate(template.TaskRunTemplate.PodTemplate),
 		}
+	}
 
-		switch value := val[keyType]; value {
-		case valSecret:
-			secretName := val[keySecretName]
-			if strings.TrimSpace(secretName) == "" {
-				errs = multierror.Append(errs, fmt.Errorf("type secret is missing %q", keySecretName))
-			}
-			binding.Secret = &v1.SecretVolumeSource{
-				SecretName: secretName,
-			}
-		case valVolume:
-			var storageClassName *string
-			if val[keyStorageClassName] != "" {
-				name := val[keyStorageClassName]
-				storageClassName = &name
-			}
-
-			binding.VolumeClaimTemplate = &v1.PersistentVolumeClaim{
-				Spec: v1.PersistentVolumeClaimSpec{
-					StorageClassName: storageClassName,
-					Resources: v1.VolumeResourceRequirements{
-						Requests: v1.ResourceList{
-							v1.ResourceStorage: *resource.NewQuantity(1, resource.BinarySI),
-						},
-					},
-					AccessModes: []v1.PersistentVolumeAccessMode{
-						v1.ReadWriteOnce,
-					},
-				},
-			}
-		default:
-			errs = multierror.Append(errs, fmt.Errorf("unknown value: %q", value))
+	if template.Timeouts != nil {
+		spec.Timeouts = &pipelinev1.TimeoutFields{
+			Pipeline: template.Timeouts.Pipeline.DeepCopy(),
+			Tasks:    template.Timeouts.Tasks.DeepCopy(),
+			Finally:  template.Timeouts.Finally.DeepCopy(),
 		}
+	}
 
-		ret = append(ret, binding)
+	for _, taskRunSpec := range template.TaskRunSpecs {
+		spec.TaskRunSpecs = append(spec.TaskRunSpecs, pipelinev1.PipelineTaskRunSpec{
+			PipelineTaskName:   taskRunSpec.PipelineTaskName,
+			ServiceAccountName: taskRunSpec.ServiceAccountName,
+			PodTemplate:        getPodTemplate(taskRunSpec.PodTemplate),
+			ComputeResources:   taskRunSpec.ComputeResources.DeepCopy(),
+		})
 	}
+}
 
-	return ret, errs
+// applyTaskRunTemplate merges the template of the Repository into the spec of the TaskRun. The timeout of the tasks
+// takes precedence over the timeout of the pipeline, per-task overrides do not apply.
+func applyTaskRunTemplate(spec *pipelinev1.TaskRunSpec, template *gollumv1alpha1.PipelineRunTemplate) {
+	if template == nil {
+		return
+	}
+
+	if template.TaskRunTemplate != nil {
+		spec.ServiceAccountName = template.TaskRunTemplate.ServiceAccountName
+		spec.PodTemplate = getPodTemplate(template.TaskRunTemplate.PodTemplate)
+	}
+
+	if template.Timeouts != nil {
+		timeout := cmp.Or(template.Timeouts.Tasks, template.Timeouts.Pipeline)
+		spec.Timeout = timeout.DeepCopy()
+	}
+}
+
+func getPodTemplate(template *gollumv1alpha1.PodTemplate) *pod.PodTemplate {
+	if template == nil {
+		return nil
+	}
+
+	return &pod.PodTemplate{
+		NodeSelector:    maps.Clone(template.NodeSelector),
+		Tolerations:     slices.Clone(template.Tolerations),
+		SecurityContext: template.SecurityContext.DeepCopy(),
+	}
 }
 
 func getParams(req CreatePipelineRunRequest) []pipelinev1.Param {
-	ret := make([]pipelinev1.Param, 0, len(req.Params))
+	ret := make([]pipelinev1.Param, 0, len(req.Params)+len(req.RenderedParams))
 
 	for key, val := range req.Params {
+		if slices.ContainsFunc(req.RenderedParams, func(p pipelinev1.Param) bool { return p.Name == key }) {
+			continue
+		}
+
 		ret = append(ret, pipelinev1.Param{
 			Name: key,
 			Value: pipelinev1.ParamValue{
@@ -132,5 +273,5 @@ func getParams(req CreatePipelineRunRequest) []pipelinev1.Param {
 		})
 	}
 
-	return ret
+	return append(ret, req.RenderedParams...)
 }
@@ -0,0 +1,244 @@
+package tekton
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/pod"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApplyPipelineRunTemplate(t *testing.T) {
+	tolerations := []v1.Toleration{{Key: "build", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule}}
+
+	tests := []struct {
+		name     string
+		template *gollumv1alpha1.PipelineRunTemplate
+		want     pipelinev1.PipelineRunSpec
+	}{
+		{
+			name: "no template",
+			want: pipelinev1.PipelineRunSpec{PipelineRef: &pipelinev1.PipelineRef{Name: "build"}},
+		},
+		{
+			name: "full template",
+			template: &gollumv1alpha1.PipelineRunTemplate{
+				TaskRunTemplate: &gollumv1alpha1.TaskRunTemplate{
+					ServiceAccountName: "builder",
+					PodTemplate: &gollumv1alpha1.PodTemplate{
+						NodeSelector: map[string]string{"node-role": "build"},
+						Tolerations:  tolerations,
+					},
+				},
+				Timeouts: &gollumv1alpha1.Timeouts{
+					Pipeline: &metav1.Duration{Duration: time.Hour},
+				},
+				TaskRunSpecs: []gollumv1alpha1.TaskRunSpec{
+					{PipelineTaskName: "push", ServiceAccountName: "pusher"},
+				},
+			},
+			want: pipelinev1.PipelineRunSpec{
+				PipelineRef: &pipelinev1.PipelineRef{Name: "build"},
+				TaskRunTemplate: pipelinev1.PipelineTaskRunTemplate{
+					ServiceAccountName: "builder",
+					PodTemplate: &pod.PodTemplate{
+						NodeSelector: map[string]string{"node-role": "build"},
+						Tolerations:  tolerations,
+					},
+				},
+				Timeouts: &pipelinev1.TimeoutFields{
+					Pipeline: &metav1.Duration{Duration: time.Hour},
+				},
+				TaskRunSpecs: []pipelinev1.PipelineTaskRunSpec{
+					{PipelineTaskName: "push", ServiceAccountName: "pusher"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pipelinev1.PipelineRunSpec{PipelineRef: &pipelinev1.PipelineRef{Name: "build"}}
+			applyPipelineRunTemplate(&got, tt.template)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applyPipelineRunTemplate() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPipelineRef(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolver *gollumv1alpha1.PipelineResolver
+		want     *pipelinev1.PipelineRef
+	}{
+		{
+			name: "no resolver",
+			want: &pipelinev1.PipelineRef{Name: "build"},
+		},
+		{
+			name: "bundles resolver",
+			resolver: &gollumv1alpha1.PipelineResolver{
+				Resolver: "bundles",
+				Params:   map[string]string{"bundle": "registry.example.com/pipelines:v1"},
+			},
+			want: &pipelinev1.PipelineRef{ResolverRef: pipelinev1.ResolverRef{
+				Resolver: "bundles",
+				Params: pipelinev1.Params{
+					{Name: "bundle", Value: *pipelinev1.NewStructuredValues("registry.example.com/pipelines:v1")},
+					{Name: "kind", Value: *pipelinev1.NewStructuredValues("pipeline")},
+					{Name: "name", Value: *pipelinev1.NewStructuredValues("build")},
+				},
+			}},
+		},
+		{
+			name: "cluster resolver with explicit kind",
+			resolver: &gollumv1alpha1.PipelineResolver{
+				Resolver: "cluster",
+				Params:   map[string]string{"kind": "pipeline", "namespace": "pipelines"},
+			},
+			want: &pipelinev1.PipelineRef{ResolverRef: pipelinev1.ResolverRef{
+				Resolver: "cluster",
+				Params: pipelinev1.Params{
+					{Name: "kind", Value: *pipelinev1.NewStructuredValues("pipeline")},
+					{Name: "name", Value: *pipelinev1.NewStructuredValues("build")},
+					{Name: "namespace", Value: *pipelinev1.NewStructuredValues("pipelines")},
+				},
+			}},
+		},
+		{
+			name: "git resolver with explicit path",
+			resolver: &gollumv1alpha1.PipelineResolver{
+				Resolver: "git",
+				Params:   map[string]string{"url": "https://github.com/owner/pipelines.git", "pathInRepo": "build.yaml"},
+			},
+			want: &pipelinev1.PipelineRef{ResolverRef: pipelinev1.ResolverRef{
+				Resolver: "git",
+				Params: pipelinev1.Params{
+					{Name: "pathInRepo", Value: *pipelinev1.NewStructuredValues("build.yaml")},
+					{Name: "url", Value: *pipelinev1.NewStructuredValues("https://github.com/owner/pipelines.git")},
+				},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getPipelineRef(CreatePipelineRunRequest{PipelineName: "build", Resolver: tt.resolver})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getPipelineRef() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetResolverRef_TaskKind(t *testing.T) {
+	req := CreatePipelineRunRequest{
+		PipelineName: "build-binary",
+		Target:       gollumv1alpha1.BuildTargetTask,
+		Resolver:     &gollumv1alpha1.PipelineResolver{Resolver: "hub"},
+	}
+
+	want := pipelinev1.ResolverRef{
+		Resolver: "hub",
+		Params: pipelinev1.Params{
+			{Name: "kind", Value: *pipelinev1.NewStructuredValues("task")},
+			{Name: "name", Value: *pipelinev1.NewStructuredValues("build-binary")},
+		},
+	}
+	if got := getResolverRef(req); !reflect.DeepEqual(got, want) {
+		t.Errorf("getResolverRef() got = %v, want %v", got, want)
+	}
+}
+
+func TestValidatePipelineResolver(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolver *gollumv1alpha1.PipelineResolver
+		target   gollumv1alpha1.BuildTarget
+		wantErr  bool
+	}{
+		{
+			name: "no resolver",
+		},
+		{
+			name:     "bundles resolver",
+			resolver: &gollumv1alpha1.PipelineResolver{Resolver: "bundles", Params: map[string]string{"bundle": "registry.example.com/pipelines:v1"}},
+		},
+		{
+			name:     "bundles resolver without bundle",
+			resolver: &gollumv1alpha1.PipelineResolver{Resolver: "bundles"},
+			wantErr:  true,
+		},
+		{
+			name:     "git resolver without url or repo",
+			resolver: &gollumv1alpha1.PipelineResolver{Resolver: "git"},
+			wantErr:  true,
+		},
+		{
+			name:     "matching kind",
+			resolver: &gollumv1alpha1.PipelineResolver{Resolver: "hub", Params: map[string]string{"kind": "task"}},
+			target:   gollumv1alpha1.BuildTargetTask,
+		},
+		{
+			name:     "task kind for pipeline",
+			resolver: &gollumv1alpha1.PipelineResolver{Resolver: "cluster", Params: map[string]string{"kind": "task"}},
+			wantErr:  true,
+		},
+		{
+			name:     "pipeline kind for task",
+			resolver: &gollumv1alpha1.PipelineResolver{Resolver: "cluster", Params: map[string]string{"kind": "pipeline"}},
+			target:   gollumv1alpha1.BuildTargetTask,
+			wantErr:  true,
+		},
+		{
+			name:     "inline pipeline spec",
+			resolver: &gollumv1alpha1.PipelineResolver{Resolver: "hub"},
+			target:   gollumv1alpha1.BuildTargetPipelineSpec,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidatePipelineResolver(tt.resolver, tt.target); (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePipelineResolver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetTaskRunSpec(t *testing.T) {
+	req := CreatePipelineRunRequest{
+		PipelineName: "build-binary",
+		Target:       gollumv1alpha1.BuildTargetTask,
+		Params:       map[string]string{ArgRevision: "v1.0.0"},
+		Template: &gollumv1alpha1.PipelineRunTemplate{
+			TaskRunTemplate: &gollumv1alpha1.TaskRunTemplate{ServiceAccountName: "builder"},
+			Timeouts: &gollumv1alpha1.Timeouts{
+				Pipeline: &metav1.Duration{Duration: time.Hour},
+				Tasks:    &metav1.Duration{Duration: 30 * time.Minute},
+			},
+		},
+	}
+
+	got, err := getTaskRunSpec(req)
+	if err != nil {
+		t.Fatalf("getTaskRunSpec() error = %v", err)
+	}
+
+	want := &pipelinev1.TaskRunSpec{
+		TaskRef:            &pipelinev1.TaskRef{Name: "build-binary"},
+		Workspaces:         []pipelinev1.WorkspaceBinding{},
+		Params:             pipelinev1.Params{{Name: ArgRevision, Value: *pipelinev1.NewStructuredValues("v1.0.0")}},
+		ServiceAccountName: "builder",
+		Timeout:            &metav1.Duration{Duration: 30 * time.Minute},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getTaskRunSpec() got = %v, want %v", got, want)
+	}
+}
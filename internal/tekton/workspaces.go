@@ -0,0 +1,229 @@
+package tekton
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// DefaultVolumeSize is the storage that is requested for volumes that do not specify a size.
+const DefaultVolumeSize = "1Gi"
+
+var accessModes = []v1.PersistentVolumeAccessMode{
+	v1.ReadWriteOnce,
+	v1.ReadOnlyMany,
+	v1.ReadWriteMany,
+	v1.ReadWriteOncePod,
+}
+
+// ValidateWorkspaceBindings returns an error if the given workspaces can not be bound to a PipelineRun.
+func ValidateWorkspaceBindings(bindings map[string]map[string]string) error {
+	_, err := getWorkspaceBindings(CreatePipelineRunRequest{WorkspaceBindings: bindings})
+	return err
+}
+
+func getWorkspaceBindings(req CreatePipelineRunRequest) ([]pipelinev1.WorkspaceBinding, error) {
+	ret := make([]pipelinev1.WorkspaceBinding, 0, len(req.WorkspaceBindings))
+	var errs error
+
+	for key, val := range req.WorkspaceBindings {
+		binding, err := getWorkspaceBinding(key, val)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("workspace %q: %w", key, err))
+		}
+		ret = append(ret, binding)
+	}
+
+	return ret, errs
+}
+
+func getWorkspaceBinding(name string, val map[string]string) (pipelinev1.WorkspaceBinding, error) {
+	binding := pipelinev1.WorkspaceBinding{
+		Name: name,
+	}
+	var errs error
+
+	switch value := val[keyType]; value {
+	case valSecret:
+		secretName := val[keySecretName]
+		if strings.TrimSpace(secretName) == "" {
+			errs = multierror.Append(errs, fmt.Errorf("type secret is missing %q", keySecretName))
+		}
+		items, err := parseItems(val[keyItems])
+		if err != nil {
+			errs = multierror.Append(errs, err)
+		}
+		binding.Secret = &v1.SecretVolumeSource{
+			SecretName: secretName,
+			Items:      items,
+		}
+	case valConfigMap:
+		configMapName := val[keyConfigMapName]
+		if strings.TrimSpace(configMapName) == "" {
+			errs = multierror.Append(errs, fmt.Errorf("type configMap is missing %q", keyConfigMapName))
+		}
+		items, err := parseItems(val[keyItems])
+		if err != nil {
+			errs = multierror.Append(errs, err)
+		}
+		binding.ConfigMap = &v1.ConfigMapVolumeSource{
+			LocalObjectReference: v1.LocalObjectReference{Name: configMapName},
+			Items:                items,
+		}
+	case valEmptyDir:
+		binding.EmptyDir = &v1.EmptyDirVolumeSource{}
+	case valPersistentVolumeClaim:
+		claimName := val[keyClaimName]
+		if strings.TrimSpace(claimName) == "" {
+			errs = multierror.Append(errs, fmt.Errorf("type persistentVolumeClaim is missing %q", keyClaimName))
+		}
+		binding.PersistentVolumeClaim = &v1.PersistentVolumeClaimVolumeSource{
+			ClaimName: claimName,
+		}
+	case valProjected:
+		sources, err := parseProjectedSources(val[keySources])
+		if err != nil {
+			errs = multierror.Append(errs, err)
+		}
+		binding.Projected = &v1.ProjectedVolumeSource{
+			Sources: sources,
+		}
+	case valCSI:
+		driver := val[keyDriver]
+		if strings.TrimSpace(driver) == "" {
+			errs = multierror.Append(errs, fmt.Errorf("type csi is missing %q", keyDriver))
+		}
+		var readOnly *bool
+		if val[keyReadOnly] != "" {
+			parsed, err := strconv.ParseBool(val[keyReadOnly])
+			if err != nil {
+				errs = multierror.Append(errs, fmt.Errorf("invalid %q: %w", keyReadOnly, err))
+			}
+			readOnly = &parsed
+		}
+		attributes, err := parseKeyValues(val[keyVolumeAttributes])
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("invalid %q: %w", keyVolumeAttributes, err))
+		}
+		binding.CSI = &v1.CSIVolumeSource{
+			Driver:           driver,
+			ReadOnly:         readOnly,
+			VolumeAttributes: attributes,
+		}
+	case valVolume:
+		var storageClassName *string
+		if val[keyStorageClassName] != "" {
+			name := val[keyStorageClassName]
+			storageClassName = &name
+		}
+
+		size, err := resource.ParseQuantity(cmp.Or(val[keySize], DefaultVolumeSize))
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("invalid %q: %w", keySize, err))
+		}
+
+		modes, err := parseAccessModes(val[keyAccessModes])
+		if err != nil {
+			errs = multierror.Append(errs, err)
+		}
+
+		binding.VolumeClaimTemplate = &v1.PersistentVolumeClaim{
+			Spec: v1.PersistentVolumeClaimSpec{
+				StorageClassName: storageClassName,
+				Resources: v1.VolumeResourceRequirements{
+					Requests: v1.ResourceList{
+						v1.ResourceStorage: size,
+					},
+				},
+				AccessModes: modes,
+			},
+		}
+	default:
+		errs = multierror.Append(errs, fmt.Errorf("unknown value: %q", value))
+	}
+
+	return binding, errs
+}
+
+// parseItems parses a comma separated list of items in the form "key=path". The path defaults to the key.
+func parseItems(value string) ([]v1.KeyToPath, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var ret []v1.KeyToPath
+	for _, item := range strings.Split(value, ",") {
+		key, path, _ := strings.Cut(strings.TrimSpace(item), "=")
+		if key == "" {
+			return nil, fmt.Errorf("invalid %q: empty key in %q", keyItems, value)
+		}
+		ret = append(ret, v1.KeyToPath{Key: key, Path: cmp.Or(path, key)})
+	}
+	return ret, nil
+}
+
+// parseAccessModes parses a comma separated list of access modes. It defaults to ReadWriteOnce.
+func parseAccessModes(value string) ([]v1.PersistentVolumeAccessMode, error) {
+	if strings.TrimSpace(value) == "" {
+		return []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce}, nil
+	}
+
+	var ret []v1.PersistentVolumeAccessMode
+	for _, mode := range strings.Split(value, ",") {
+		accessMode := v1.PersistentVolumeAccessMode(strings.TrimSpace(mode))
+		if !slices.Contains(accessModes, accessMode) {
+			return nil, fmt.Errorf("invalid %q: unknown access mode %q", keyAccessModes, accessMode)
+		}
+		ret = append(ret, accessMode)
+	}
+	return ret, nil
+}
+
+// parseProjectedSources parses a comma separated list of sources in the form "secret/name" or "configMap/name".
+func parseProjectedSources(value string) ([]v1.VolumeProjection, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, fmt.Errorf("type projected is missing %q", keySources)
+	}
+
+	var ret []v1.VolumeProjection
+	for _, source := range strings.Split(value, ",") {
+		kind, name, _ := strings.Cut(strings.TrimSpace(source), "/")
+		if name == "" {
+			return nil, fmt.Errorf("invalid %q: missing name in %q", keySources, source)
+		}
+
+		switch kind {
+		case valSecret:
+			ret = append(ret, v1.VolumeProjection{Secret: &v1.SecretProjection{LocalObjectReference: v1.LocalObjectReference{Name: name}}})
+		case valConfigMap:
+			ret = append(ret, v1.VolumeProjection{ConfigMap: &v1.ConfigMapProjection{LocalObjectReference: v1.LocalObjectReference{Name: name}}})
+		default:
+			return nil, fmt.Errorf("invalid %q: unknown source %q", keySources, kind)
+		}
+	}
+	return ret, nil
+}
+
+// parseKeyValues parses a comma separated list of "key=value" pairs.
+func parseKeyValues(value string) (map[string]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	ret := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		key, val, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("expected key=value, got %q", pair)
+		}
+		ret[key] = val
+	}
+	return ret, nil
+}
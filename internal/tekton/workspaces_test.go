@@ -0,0 +1,118 @@
+package tekton
+
+import (
+	"reflect"
+	"testing"
+
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestGetWorkspaceBinding(t *testing.T) {
+	readOnly := true
+	storageClassName := "openebs-hostpath"
+
+	tests := []struct {
+		name    string
+		val     map[string]string
+		want    pipelinev1.WorkspaceBinding
+		wantErr bool
+	}{
+		{
+			name: "secret with items",
+			val:  map[string]string{"type": "secret", "secretName": "signify", "items": "key=signify.sec, key.pub"},
+			want: pipelinev1.WorkspaceBinding{Name: "ws", Secret: &v1.SecretVolumeSource{
+				SecretName: "signify",
+				Items:      []v1.KeyToPath{{Key: "key", Path: "signify.sec"}, {Key: "key.pub", Path: "key.pub"}},
+			}},
+		},
+		{
+			name:    "secret without name",
+			val:     map[string]string{"type": "secret"},
+			wantErr: true,
+		},
+		{
+			name: "configMap",
+			val:  map[string]string{"type": "configMap", "configMapName": "build-config"},
+			want: pipelinev1.WorkspaceBinding{Name: "ws", ConfigMap: &v1.ConfigMapVolumeSource{
+				LocalObjectReference: v1.LocalObjectReference{Name: "build-config"},
+			}},
+		},
+		{
+			name: "emptyDir",
+			val:  map[string]string{"type": "emptyDir"},
+			want: pipelinev1.WorkspaceBinding{Name: "ws", EmptyDir: &v1.EmptyDirVolumeSource{}},
+		},
+		{
+			name: "persistentVolumeClaim",
+			val:  map[string]string{"type": "persistentVolumeClaim", "claimName": "output"},
+			want: pipelinev1.WorkspaceBinding{Name: "ws", PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "output"}},
+		},
+		{
+			name: "projected",
+			val:  map[string]string{"type": "projected", "sources": "secret/github,configMap/ca-bundle"},
+			want: pipelinev1.WorkspaceBinding{Name: "ws", Projected: &v1.ProjectedVolumeSource{Sources: []v1.VolumeProjection{
+				{Secret: &v1.SecretProjection{LocalObjectReference: v1.LocalObjectReference{Name: "github"}}},
+				{ConfigMap: &v1.ConfigMapProjection{LocalObjectReference: v1.LocalObjectReference{Name: "ca-bundle"}}},
+			}}},
+		},
+		{
+			name:    "projected with unknown source",
+			val:     map[string]string{"type": "projected", "sources": "downwardAPI/labels"},
+			wantErr: true,
+		},
+		{
+			name: "csi",
+			val:  map[string]string{"type": "csi", "driver": "secrets-store.csi.k8s.io", "readOnly": "true", "volumeAttributes": "secretProviderClass=vault"},
+			want: pipelinev1.WorkspaceBinding{Name: "ws", CSI: &v1.CSIVolumeSource{
+				Driver:           "secrets-store.csi.k8s.io",
+				ReadOnly:         &readOnly,
+				VolumeAttributes: map[string]string{"secretProviderClass": "vault"},
+			}},
+		},
+		{
+			name: "volume with defaults",
+			val:  map[string]string{"type": "volume"},
+			want: pipelinev1.WorkspaceBinding{Name: "ws", VolumeClaimTemplate: &v1.PersistentVolumeClaim{Spec: v1.PersistentVolumeClaimSpec{
+				Resources:   v1.VolumeResourceRequirements{Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("1Gi")}},
+				AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+			}}},
+		},
+		{
+			name: "volume",
+			val:  map[string]string{"type": "volume", "storageClassName": storageClassName, "size": "20Gi", "accessModes": "ReadWriteOnce,ReadWriteMany"},
+			want: pipelinev1.WorkspaceBinding{Name: "ws", VolumeClaimTemplate: &v1.PersistentVolumeClaim{Spec: v1.PersistentVolumeClaimSpec{
+				StorageClassName: &storageClassName,
+				Resources:        v1.VolumeResourceRequirements{Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("20Gi")}},
+				AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce, v1.ReadWriteMany},
+			}}},
+		},
+		{
+			name:    "volume with unknown access mode",
+			val:     map[string]string{"type": "volume", "accessModes": "ReadWriteSometimes"},
+			wantErr: true,
+		},
+		{
+			name:    "volume with invalid size",
+			val:     map[string]string{"type": "volume", "size": "lots"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			val:     map[string]string{"type": "nfs"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getWorkspaceBinding("ws", tt.val)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getWorkspaceBinding() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getWorkspaceBinding() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
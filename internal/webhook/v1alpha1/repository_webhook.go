@@ -0,0 +1,204 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+	"github.com/soerenschneider/gollum/internal/requeue"
+	"github.com/soerenschneider/gollum/internal/tekton"
+	"github.com/soerenschneider/gollum/internal/versionfilter"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const versionFilterSemver = "semver"
+
+var repositorylog = logf.Log.WithName("repository-resource")
+
+// SetupRepositoryWebhookWithManager registers the webhook for Repository in the manager.
+func SetupRepositoryWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&gollumv1alpha1.Repository{}).
+		WithValidator(&RepositoryCustomValidator{Client: mgr.GetClient()}).
+		WithDefaulter(&RepositoryCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-gollum-soeren-cloud-v1alpha1-repository,mutating=true,failurePolicy=fail,sideEffects=None,groups=gollum.soeren.cloud,resources=repositories,verbs=create;update,versions=v1alpha1,name=mrepository-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// RepositoryCustomDefaulter sets default values on the Repository when it is created or updated.
+type RepositoryCustomDefaulter struct{}
+
+var _ admission.CustomDefaulter = &RepositoryCustomDefaulter{}
+
+// Default implements admission.CustomDefaulter.
+func (d *RepositoryCustomDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	repository, ok := obj.(*gollumv1alpha1.Repository)
+	if !ok {
+		return fmt.Errorf("expected a Repository object but got %T", obj)
+	}
+	repositorylog.V(1).Info("defaulting", "name", repository.GetName())
+
+	repository.Spec.Owner = strings.TrimSpace(repository.Spec.Owner)
+	repository.Spec.Repository = strings.TrimSpace(repository.Spec.Repository)
+	if repository.Spec.VersionFilter != nil && repository.Spec.VersionFilter.Impl == "" {
+		repository.Spec.VersionFilter.Impl = versionFilterSemver
+	}
+
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-gollum-soeren-cloud-v1alpha1-repository,mutating=false,failurePolicy=fail,sideEffects=None,groups=gollum.soeren.cloud,resources=repositories,verbs=create;update,versions=v1alpha1,name=vrepository-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// RepositoryCustomValidator rejects Repositories that can not be reconciled and Repositories that watch the same
+// repository as another Repository in the namespace.
+type RepositoryCustomValidator struct {
+	Client client.Reader
+}
+
+var _ admission.CustomValidator = &RepositoryCustomValidator{}
+
+// ValidateCreate implements admission.CustomValidator.
+func (v *RepositoryCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	repository, ok := obj.(*gollumv1alpha1.Repository)
+	if !ok {
+		return nil, fmt.Errorf("expected a Repository object but got %T", obj)
+	}
+	repositorylog.V(1).Info("validation for create", "name", repository.GetName())
+
+	return v.validate(ctx, repository)
+}
+
+// ValidateUpdate implements admission.CustomValidator.
+func (v *RepositoryCustomValidator) ValidateUpdate(ctx context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	repository, ok := newObj.(*gollumv1alpha1.Repository)
+	if !ok {
+		return nil, fmt.Errorf("expected a Repository object for the newObj but got %T", newObj)
+	}
+	repositorylog.V(1).Info("validation for update", "name", repository.GetName())
+
+	return v.validate(ctx, repository)
+}
+
+// ValidateDelete implements admission.CustomValidator.
+func (v *RepositoryCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *RepositoryCustomValidator) validate(ctx context.Context, repository *gollumv1alpha1.Repository) (admission.Warnings, error) {
+	warnings, allErrs := validateRepositorySpec(&repository.Spec, field.NewPath("spec"))
+
+	if len(allErrs) == 0 {
+		duplicate, err := v.findDuplicate(ctx, repository)
+		if err != nil {
+			return warnings, fmt.Errorf("could not check for duplicate repositories: %w", err)
+		}
+		if duplicate != "" {
+			allErrs = append(allErrs, field.Duplicate(field.NewPath("spec", "repo"),
+				fmt.Sprintf("%s/%s is already watched by Repository %q", repository.Spec.Owner, repository.Spec.Repository, duplicate)))
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return warnings, nil
+	}
+	return warnings, apierrors.NewInvalid(gollumv1alpha1.GroupVersion.WithKind("Repository").GroupKind(), repository.Name, allErrs)
+}
+
+// findDuplicate returns the name of another Repository in the namespace that watches the same repository of the same
+// provider instance.
+func (v *RepositoryCustomValidator) findDuplicate(ctx context.Context, repository *gollumv1alpha1.Repository) (string, error) {
+	repos := &gollumv1alpha1.RepositoryList{}
+	if err := v.Client.List(ctx, repos, client.InNamespace(repository.Namespace)); err != nil {
+		return "", err
+	}
+
+	for _, other := range repos.Items {
+		if other.Name == repository.Name {
+			continue
+		}
+		if strings.EqualFold(other.Spec.Owner, repository.Spec.Owner) && strings.EqualFold(other.Spec.Repository, repository.Spec.Repository) &&
+			getProviderKey(other.Spec.Provider) == getProviderKey(repository.Spec.Provider) {
+			return other.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// getProviderKey returns a key identifying the provider instance. The URL is ignored for GitHub, as it is not used to
+// reach GitHub.
+func getProviderKey(provider *gollumv1alpha1.ProviderSpec) string {
+	if provider == nil || provider.Type == "" || provider.Type == gollumv1alpha1.ProviderGithub {
+		return string(gollumv1alpha1.ProviderGithub)
+	}
+	baseUrl := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(provider.URL), "/"))
+	return string(provider.Type) + "/" + baseUrl
+}
+
+func validateRepositorySpec(spec *gollumv1alpha1.RepositorySpec, path *field.Path) (admission.Warnings, field.ErrorList) {
+	var warnings admission.Warnings
+	var allErrs field.ErrorList
+
+	if strings.TrimSpace(spec.Owner) == "" {
+		allErrs = append(allErrs, field.Required(path.Child("owner"), "owner must not be empty"))
+	}
+	if strings.TrimSpace(spec.Repository) == "" {
+		allErrs = append(allErrs, field.Required(path.Child("repo"), "repo must not be empty"))
+	}
+
+	if len(spec.PipelineNames) == 0 && spec.BuildProfileRef == nil {
+		warnings = append(warnings, "no pipelines are configured, missing artifacts will not be built")
+	}
+	for artifactType, pipelineName := range spec.PipelineNames {
+		if !slices.Contains(gollumv1alpha1.ArtifactTypes(), artifactType) {
+			allErrs = append(allErrs, field.NotSupported(path.Child("pipelineNames"), artifactType, gollumv1alpha1.ArtifactTypes()))
+		} else if strings.TrimSpace(pipelineName) == "" {
+			allErrs = append(allErrs, field.Required(path.Child("pipelineNames").Key(string(artifactType)), "pipeline name must not be empty"))
+		}
+	}
+
+	if spec.VersionFilter != nil && spec.VersionFilter.Impl == versionFilterSemver {
+		if _, err := versionfilter.NewSemver(spec.VersionFilter.Arg); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("versionFilter", "arg"), spec.VersionFilter.Arg, err.Error()))
+		}
+	}
+
+	if err := tekton.ValidateWorkspaceBindings(spec.Workspaces); err != nil {
+		allErrs = append(allErrs, field.Invalid(path.Child("workspaces"), spec.Workspaces, err.Error()))
+	}
+
+	if err := tekton.ValidatePipelineResolver(spec.PipelineResolver, spec.BuildTarget); err != nil {
+		allErrs = append(allErrs, field.Invalid(path.Child("pipelineResolver"), spec.PipelineResolver, err.Error()))
+	}
+
+	if err := tekton.ValidateParams(spec.Params); err != nil {
+		allErrs = append(allErrs, field.Invalid(path.Child("params"), spec.Params, err.Error()))
+	}
+
+	if spec.Schedule != nil && spec.Schedule.Cron != "" {
+		if _, err := requeue.ParseCron(spec.Schedule.Cron); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("schedule", "cron"), spec.Schedule.Cron, err.Error()))
+		}
+	}
+
+	if spec.BuildWindow != nil {
+		for i, window := range spec.BuildWindow.Windows {
+			if _, err := requeue.ParseWindow(window); err != nil {
+				allErrs = append(allErrs, field.Invalid(path.Child("buildWindow", "windows").Index(i), window, err.Error()))
+			}
+		}
+		if _, err := time.LoadLocation(spec.BuildWindow.TimeZone); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("buildWindow", "timeZone"), spec.BuildWindow.TimeZone, err.Error()))
+		}
+	}
+
+	return warnings, allErrs
+}
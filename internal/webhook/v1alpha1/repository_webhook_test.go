@@ -0,0 +1,251 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	gollumv1alpha1 "github.com/soerenschneider/gollum/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func validSpec() gollumv1alpha1.RepositorySpec {
+	return gollumv1alpha1.RepositorySpec{
+		Owner:      "soerenschneider",
+		Repository: "tunnelguard",
+		RepositoryConfig: gollumv1alpha1.RepositoryConfig{
+			PipelineNames: map[gollumv1alpha1.ArtifactType]string{
+				gollumv1alpha1.ArtifactsKeyReleaseAssets: "build-gh-release",
+			},
+			VersionFilter: &gollumv1alpha1.VersionFilterSpec{
+				Impl: "semver",
+				Arg:  ">= v1.0.0",
+			},
+			Workspaces: map[string]map[string]string{
+				"signify":     {"type": "secret", "secretName": "signify"},
+				"shared-data": {"type": "volume"},
+			},
+		},
+	}
+}
+
+func TestValidateRepositorySpec(t *testing.T) {
+	tests := []struct {
+		name         string
+		mutate       func(spec *gollumv1alpha1.RepositorySpec)
+		wantErrs     int
+		wantWarnings int
+	}{
+		{
+			name:   "valid",
+			mutate: func(_ *gollumv1alpha1.RepositorySpec) {},
+		},
+		{
+			name: "empty owner",
+			mutate: func(spec *gollumv1alpha1.RepositorySpec) {
+				spec.Owner = " "
+			},
+			wantErrs: 1,
+		},
+		{
+			name: "invalid semver constraint",
+			mutate: func(spec *gollumv1alpha1.RepositorySpec) {
+				spec.VersionFilter.Arg = ">= banana"
+			},
+			wantErrs: 1,
+		},
+		{
+			name: "unknown workspace type",
+			mutate: func(spec *gollumv1alpha1.RepositorySpec) {
+				spec.Workspaces["shared-data"]["type"] = "nfs"
+			},
+			wantErrs: 1,
+		},
+		{
+			name: "bundles resolver without bundle",
+			mutate: func(spec *gollumv1alpha1.RepositorySpec) {
+				spec.PipelineResolver = &gollumv1alpha1.PipelineResolver{Resolver: "bundles"}
+			},
+			wantErrs: 1,
+		},
+		{
+			name: "resolver with inline pipeline spec",
+			mutate: func(spec *gollumv1alpha1.RepositorySpec) {
+				spec.BuildTarget = gollumv1alpha1.BuildTargetPipelineSpec
+				spec.PipelineResolver = &gollumv1alpha1.PipelineResolver{Resolver: "cluster"}
+			},
+			wantErrs: 1,
+		},
+		{
+			name: "param with invalid template",
+			mutate: func(spec *gollumv1alpha1.RepositorySpec) {
+				spec.Params = []gollumv1alpha1.Param{{Name: "version", Value: "{{ .Version"}}
+			},
+			wantErrs: 1,
+		},
+		{
+			name: "param referring to unknown field",
+			mutate: func(spec *gollumv1alpha1.RepositorySpec) {
+				spec.Params = []gollumv1alpha1.Param{{Name: "version", Value: "{{ .Unknown }}"}}
+			},
+			wantErrs: 1,
+		},
+		{
+			name: "params with duplicate names",
+			mutate: func(spec *gollumv1alpha1.RepositorySpec) {
+				spec.Params = []gollumv1alpha1.Param{{Name: "version", Value: "{{ .Version }}"}, {Name: "version", Value: "{{ .Tag }}"}}
+			},
+			wantErrs: 1,
+		},
+		{
+			name: "secret workspace without secret name",
+			mutate: func(spec *gollumv1alpha1.RepositorySpec) {
+				delete(spec.Workspaces["signify"], "secretName")
+			},
+			wantErrs: 1,
+		},
+		{
+			name: "unsupported artifact type",
+			mutate: func(spec *gollumv1alpha1.RepositorySpec) {
+				spec.PipelineNames["binaries"] = "build-binaries"
+			},
+			wantErrs: 1,
+		},
+		{
+			name: "invalid cron expression",
+			mutate: func(spec *gollumv1alpha1.RepositorySpec) {
+				spec.Schedule = &gollumv1alpha1.ScheduleSpec{Cron: "0 25 * * *"}
+			},
+			wantErrs: 1,
+		},
+		{
+			name: "invalid build window",
+			mutate: func(spec *gollumv1alpha1.RepositorySpec) {
+				spec.BuildWindow = &gollumv1alpha1.BuildWindowSpec{Windows: []string{"mon-fri 22:00"}, TimeZone: "Mars/Olympus"}
+			},
+			wantErrs: 2,
+		},
+		{
+			name: "no pipelines",
+			mutate: func(spec *gollumv1alpha1.RepositorySpec) {
+				spec.PipelineNames = nil
+			},
+			wantWarnings: 1,
+		},
+		{
+			name: "pipelines from build profile",
+			mutate: func(spec *gollumv1alpha1.RepositorySpec) {
+				spec.PipelineNames = nil
+				spec.BuildProfileRef = &gollumv1alpha1.LocalObjectReference{Name: "go-release"}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := validSpec()
+			tt.mutate(&spec)
+			warnings, errs := validateRepositorySpec(&spec, field.NewPath("spec"))
+			if len(errs) != tt.wantErrs {
+				t.Errorf("validateRepositorySpec() errs = %v, want %d errors", errs, tt.wantErrs)
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("validateRepositorySpec() warnings = %v, want %d warnings", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestRepositoryCustomValidator_Duplicate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := gollumv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	existing := &gollumv1alpha1.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "tunnelguard", Namespace: "default"},
+		Spec:       validSpec(),
+	}
+	existingGitea := &gollumv1alpha1.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "tunnelguard-codeberg", Namespace: "default"},
+		Spec:       validSpec(),
+	}
+	existingGitea.Spec.Provider = &gollumv1alpha1.ProviderSpec{Type: gollumv1alpha1.ProviderGitea, URL: "https://codeberg.org/"}
+	validator := &RepositoryCustomValidator{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing, existingGitea).Build(),
+	}
+
+	tests := []struct {
+		name      string
+		objName   string
+		namespace string
+		owner     string
+		provider  *gollumv1alpha1.ProviderSpec
+		wantErr   bool
+	}{
+		{
+			name:      "same owner and repo",
+			objName:   "tunnelguard-2",
+			namespace: "default",
+			owner:     "SoerenSchneider",
+			wantErr:   true,
+		},
+		{
+			name:      "update of the existing repository",
+			objName:   "tunnelguard",
+			namespace: "default",
+			owner:     "soerenschneider",
+		},
+		{
+			name:      "other namespace",
+			objName:   "tunnelguard-2",
+			namespace: "other",
+			owner:     "soerenschneider",
+		},
+		{
+			name:      "other owner",
+			objName:   "tunnelguard-2",
+			namespace: "default",
+			owner:     "someone",
+		},
+		{
+			name:      "explicit github provider",
+			objName:   "tunnelguard-2",
+			namespace: "default",
+			owner:     "soerenschneider",
+			provider:  &gollumv1alpha1.ProviderSpec{Type: gollumv1alpha1.ProviderGithub},
+			wantErr:   true,
+		},
+		{
+			name:      "same gitea instance",
+			objName:   "tunnelguard-2",
+			namespace: "default",
+			owner:     "soerenschneider",
+			provider:  &gollumv1alpha1.ProviderSpec{Type: gollumv1alpha1.ProviderGitea, URL: "https://Codeberg.org"},
+			wantErr:   true,
+		},
+		{
+			name:      "other gitea instance",
+			objName:   "tunnelguard-2",
+			namespace: "default",
+			owner:     "soerenschneider",
+			provider:  &gollumv1alpha1.ProviderSpec{Type: gollumv1alpha1.ProviderGitea, URL: "https://gitea.example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &gollumv1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: tt.objName, Namespace: tt.namespace},
+				Spec:       validSpec(),
+			}
+			repository.Spec.Owner = tt.owner
+			repository.Spec.Provider = tt.provider
+
+			_, err := validator.ValidateCreate(context.Background(), repository)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCreate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}